@@ -0,0 +1,111 @@
+// Evaluates app-level dpi evasion strategies ("what works here", like zapret's blockcheck): if the tls handshake
+// with a host fails, it is retried with a split, fragmented, case-mixed or padded ClientHello.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/netip"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type BypassSingleOpt struct {
+	Ctx  context.Context
+	Ip   netip.Addr
+	Port int
+	Sni  string
+}
+
+type BypassStrategyItem struct {
+	Strategy string
+	Err      error // handshake outcome
+}
+
+type BypassSingleResult struct {
+	IpInfo     inetlookup.IpInfo
+	Port       int
+	Sni        string
+	Baseline   error // handshake without any evasion
+	Strategies []BypassStrategyItem
+}
+
+var ErrBypassUnknownStrategy = errors.New("bypass: unknown strategy")
+
+const (
+	BypassTcpSplit     = "tcp-split"     // two tcp segments split in the middle of the sni
+	BypassRecordSplit  = "record-split"  // two tls records split in the middle of the sni
+	BypassSniCase      = "sni-case"      // mixed case sni (e.g. WwW.ExAmPlE.CoM)
+	BypassPadding      = "padding"       // large padding extension
+	BypassTinySegments = "tiny-segments" // tcp_nodelay and tiny tcp segments
+)
+
+// Strategies are evaluated only if the baseline handshake fails (i.e. the host is blocked).
+func BypassSingle(opt BypassSingleOpt) BypassSingleResult {
+	cfg := config.Get().Checkers.Bypass
+	res := BypassSingleResult{
+		IpInfo: inetlookup.Default().IpInfo(opt.Ip),
+		Port:   opt.Port,
+		Sni:    opt.Sni,
+	}
+
+	tlsConnOpt := inetutil.TlsConnOpt{
+		Ctx:                 opt.Ctx,
+		Ip:                  opt.Ip,
+		Port:                opt.Port,
+		Sni:                 opt.Sni,
+		TcpConnTimeout:      cfg.TcpConnTimeout,
+		TlsHandshakeTimeout: cfg.TlsHandshakeTimeout,
+	}
+	res.Baseline = bypassHandshake(tlsConnOpt)
+
+	for _, strategy := range cfg.Strategies {
+		item := BypassStrategyItem{Strategy: strategy}
+		switch {
+		case res.Baseline == nil || opt.Ctx.Err() != nil:
+			item.Err = ErrWebhostSkip
+		default:
+			strategyOpt, err := bypassTlsConnOpt(strategy, tlsConnOpt)
+			if err == nil {
+				err = bypassHandshake(strategyOpt)
+			}
+			item.Err = err
+		}
+		res.Strategies = append(res.Strategies, item)
+	}
+
+	log.Println("bypass; ip:", opt.Ip, "sni:", opt.Sni, "baseline:", res.Baseline, "strategies:", res.Strategies)
+	return res
+}
+
+func bypassHandshake(tlsConnOpt inetutil.TlsConnOpt) error {
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
+	if err == nil {
+		tlsConn.Close()
+	}
+	return err
+}
+
+func bypassTlsConnOpt(strategy string, tlsConnOpt inetutil.TlsConnOpt) (inetutil.TlsConnOpt, error) {
+	cfg := config.Get().Checkers.Bypass
+	switch strategy {
+	case BypassTcpSplit:
+		tlsConnOpt.HelloWrite = inetutil.HelloSplitWrite(cfg.SplitDelay)
+	case BypassRecordSplit:
+		tlsConnOpt.HelloWrite = inetutil.HelloRecordSplitWrite()
+	case BypassSniCase:
+		tlsConnOpt.Sni = httpHostMangle(tlsConnOpt.Sni)
+	case BypassPadding:
+		tlsConnOpt.HelloPadding = cfg.Padding
+	case BypassTinySegments:
+		tlsConnOpt.HelloWrite = inetutil.HelloChunkedWrite(cfg.TinySegmentSize, cfg.TinySegmentDelay)
+	default:
+		log.Println("bypass; unknown strategy:", strategy)
+		return tlsConnOpt, ErrBypassUnknownStrategy
+	}
+	return tlsConnOpt, nil
+}
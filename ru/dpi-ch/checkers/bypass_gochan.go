@@ -0,0 +1,34 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+type BypassGochanOut struct {
+	Bag WebhostGochanBag
+	Out BypassSingleResult
+}
+
+type BypassGochanRunnerOut struct {
+	Out      <-chan BypassGochanOut
+	Progress <-chan string
+}
+
+func BypassGochanRunner(ctx context.Context) BypassGochanRunnerOut {
+	cfg := config.Get().Checkers.Bypass
+	out, progress := webhostFarmRunner(webhostFarmRunnerOpt[BypassGochanOut]{
+		Ctx:     ctx,
+		Targets: cfg.Targets,
+		Name:    "bypass",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) BypassGochanOut {
+			return BypassGochanOut{
+				Bag: bag,
+				Out: BypassSingle(BypassSingleOpt{Ctx: in.Ctx, Ip: in.Ip, Port: in.Port, Sni: in.Sni}),
+			}
+		},
+	})
+	return BypassGochanRunnerOut{Out: out, Progress: progress}
+}
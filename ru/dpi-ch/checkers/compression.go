@@ -0,0 +1,193 @@
+// Checks if a censor cuts off compressed http responses before a minimum size (content-level dpi)
+
+package checkers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type CompressionOpt struct {
+	Ctx      context.Context
+	Url      string
+	Encoding string // gzip, deflate, br or zstd
+}
+
+type CompressionResult struct {
+	Url          string
+	Encoding     string
+	HttpStatus   int
+	Compressed   int64
+	Decompressed int64
+	Verdict      error
+}
+
+var (
+	ErrCompressionNotSupported = errors.New("compression: not supported")
+	ErrCompressionEofBeforeMin = errors.New("compression: eof before min")
+	ErrCompressionTimeout      = errors.New("compression: timeout")
+	ErrCompressionConnErr      = errors.New("compression: connection error")
+	ErrCompressionInternal     = errors.New("compression: internal error")
+)
+
+var CompressionEncodings = []string{"gzip", "deflate", "br", "zstd"}
+
+// Requests url with the specified Accept-Encoding and reads at least min-bytes of compressed data
+// (or everything if min-bytes is -1), decompressing it on the fly.
+func Compression(opt CompressionOpt) CompressionResult {
+	cfg := config.Get().Checkers.Compression
+	res := CompressionResult{Url: opt.Url, Encoding: opt.Encoding}
+
+	ctx, cancel := context.WithTimeout(opt.Ctx, cfg.Timeout)
+	defer cancel()
+
+	u, err := url.Parse(opt.Url)
+	if err != nil || u.Scheme != "https" {
+		log.Println("compression/url", opt.Url, err)
+		res.Verdict = ErrCompressionInternal
+		return res
+	}
+
+	port := 443
+	if u.Port() != "" {
+		port, _ = strconv.Atoi(u.Port())
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip4", u.Hostname())
+	if err != nil || len(ips) == 0 {
+		log.Println("compression/lookup", u.Hostname(), err)
+		res.Verdict = ErrCompressionConnErr
+		return res
+	}
+
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(inetutil.TlsConnOpt{
+		Ctx:            ctx,
+		Ip:             ips[0],
+		Port:           port,
+		Sni:            u.Hostname(),
+		TcpConnTimeout: cfg.TcpConnTimeout,
+	})
+	if err != nil {
+		res.Verdict = compressionInetutilVerdict(err)
+		return res
+	}
+	defer tlsConn.Close()
+
+	req, err := http.NewRequest("GET", opt.Url, http.NoBody)
+	if err != nil {
+		res.Verdict = ErrCompressionInternal
+		return res
+	}
+	req.Close = true
+	inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+	req.Header.Set("Accept-Encoding", opt.Encoding)
+
+	if _, err := inetutil.TlsWriteHttpRequest(ctx, tlsConn, req); err != nil {
+		res.Verdict = compressionInetutilVerdict(err)
+		return res
+	}
+
+	resp, err := inetutil.TlsReadHttpResponse(ctx, tlsConn, bufio.NewReader(tlsConn))
+	if err != nil {
+		res.Verdict = compressionInetutilVerdict(err)
+		return res
+	}
+	defer resp.Body.Close()
+	res.HttpStatus = resp.StatusCode
+
+	// the body is read directly from the connection, so the deadline is set for the whole check
+	deadline, _ := ctx.Deadline()
+	tlsConn.SetReadDeadline(deadline)
+
+	if resp.Header.Get("Content-Encoding") == "" {
+		res.Verdict = ErrCompressionNotSupported
+		return res
+	}
+
+	compressedCr := &inetutil.CountingReader{Reader: resp.Body}
+	decompressor, err := compressionDecompressor(opt.Encoding, compressedCr)
+	if err != nil {
+		res.Compressed = compressedCr.Bytes
+		res.Verdict = compressionReadVerdict(err)
+		return res
+	}
+	defer decompressor.Close()
+
+	for cfg.MinBytes == -1 || compressedCr.Bytes < int64(cfg.MinBytes) {
+		n, err := io.CopyN(io.Discard, decompressor, int64(cfg.Chunk))
+		res.Decompressed += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			if cfg.MinBytes != -1 && compressedCr.Bytes < int64(cfg.MinBytes) {
+				res.Verdict = ErrCompressionEofBeforeMin
+			}
+			break
+		}
+		if err != nil {
+			res.Verdict = compressionReadVerdict(err)
+			break
+		}
+	}
+
+	res.Compressed = compressedCr.Bytes
+	return res
+}
+
+func compressionDecompressor(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		return zlib.NewReader(r)
+	case "br":
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case "zstd":
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return nil, ErrCompressionInternal
+	}
+}
+
+func compressionInetutilVerdict(err error) error {
+	switch err {
+	case inetutil.ErrTcpConnTimeout, inetutil.ErrTlsHandshakeTimeout,
+		inetutil.ErrTcpReadTimeout, inetutil.ErrTcpWriteTimeout:
+		return ErrCompressionTimeout
+	}
+	log.Println("compression", err)
+	return ErrCompressionConnErr
+}
+
+func compressionReadVerdict(err error) error {
+	if errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrCompressionTimeout
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCompressionEofBeforeMin
+	}
+	if _, ok := errors.AsType[net.Error](err); ok {
+		return ErrCompressionConnErr
+	}
+
+	log.Println("compression/read", err)
+	return ErrCompressionInternal
+}
@@ -0,0 +1,34 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/gochan"
+)
+
+func CompressionGochan(ctx context.Context) <-chan CompressionResult {
+	cfg := config.Get().Checkers.Compression
+	in := make(chan CompressionOpt)
+	out := gochan.Start(gochan.GochanOpt[CompressionOpt, CompressionResult]{
+		Ctx:      ctx,
+		Workers:  cfg.Workers,
+		Input:    in,
+		Executor: Compression,
+	})
+
+	encodings := cfg.Encodings
+	if len(encodings) == 0 {
+		encodings = CompressionEncodings
+	}
+
+	items := []CompressionOpt{}
+	for _, u := range cfg.Targets {
+		for _, e := range encodings {
+			items = append(items, CompressionOpt{Ctx: ctx, Url: u, Encoding: e})
+		}
+	}
+
+	gochan.Push(ctx, in, items)
+	return out
+}
@@ -0,0 +1,84 @@
+package checkers
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+func TestCompression(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.Compression
+	cfg.Timeout = 500 * time.Millisecond
+
+	// random data doesn't shrink, so the compressed size is about the same
+	data := make([]byte, 2*cfg.MinBytes)
+	rand.Read(data)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var enc interface {
+			io.WriteCloser
+			Flush() error
+		}
+		switch r.Header.Get("Accept-Encoding") {
+		case "gzip":
+			enc = gzip.NewWriter(w)
+		case "zstd":
+			enc, _ = zstd.NewWriter(w)
+		default:
+			w.Write(data)
+			return
+		}
+		w.Header().Set("Content-Encoding", r.Header.Get("Accept-Encoding"))
+
+		switch r.URL.Path {
+		case "/cut":
+			enc.Write(data[:cfg.MinBytes/4])
+			enc.Flush()
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case "/stall":
+			enc.Write(data[:cfg.MinBytes/4])
+			enc.Flush()
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			enc.Write(data)
+			enc.Close()
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		path     string
+		encoding string
+		want     error
+	}{
+		{"/", "gzip", nil},
+		{"/", "zstd", nil},
+		{"/", "deflate", ErrCompressionNotSupported},
+		{"/cut", "gzip", ErrCompressionEofBeforeMin},
+		{"/stall", "zstd", ErrCompressionTimeout},
+	}
+	for _, c := range cases {
+		res := Compression(CompressionOpt{Ctx: context.Background(), Url: srv.URL + c.path, Encoding: c.encoding})
+		if res.Verdict != c.want {
+			t.Errorf("%s %s: got %v, want %v", c.path, c.encoding, res.Verdict, c.want)
+		}
+		if c.want == nil && (res.Compressed < int64(cfg.MinBytes) || res.Decompressed == 0) {
+			t.Errorf("%s %s: got %d compressed, %d decompressed bytes", c.path, c.encoding, res.Compressed, res.Decompressed)
+		}
+	}
+}
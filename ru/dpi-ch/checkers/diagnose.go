@@ -0,0 +1,351 @@
+// Diagnoses a single host ("X doesn't work") layer by layer: dns (system vs trusted DoH), tcp connect,
+// tls (real, empty, random and fallback snis), http alive, tcp 16-20 and "siberian" restrictions.
+// The first layer that is interfered with is the root cause; the evidence is collected for each layer.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+
+	tls "github.com/refraction-networking/utls"
+)
+
+type DiagnoseStep struct {
+	Layer    string
+	Err      error // nil is no interference; ErrWebhostSkip if the layer is not reached
+	Evidence []string
+}
+
+var (
+	ErrDiagnoseDnsUnresolved = errors.New("dns: host is not resolved (even by trusted DoH)")
+	ErrDiagnoseDnsBlocked    = errors.New("dns: system resolver fails, but trusted DoH resolves")
+	ErrDiagnoseDnsSpoofed    = errors.New("dns: system resolver answer is spoofed")
+	ErrDiagnoseTcp           = errors.New("tcp: connection fails (ip blocking or host is down)")
+	ErrDiagnoseTlsSni        = errors.New("tls: blocked by sni")
+	ErrDiagnoseTls           = errors.New("tls: handshake fails with any sni")
+	ErrDiagnoseHttp          = errors.New("http: no response after the handshake")
+	ErrDiagnoseTcp1620       = errors.New("tcp 16-20: connection freezes after ~16-20 KB")
+	ErrDiagnoseSiberian      = errors.New("siberian: restriction is detected")
+)
+
+// The host is reachable, but the isp answers with its stub page instead.
+type DiagnoseBlockpageError struct {
+	Isp string
+}
+
+func (e *DiagnoseBlockpageError) Error() string {
+	return "http: isp stub page instead of the response (" + e.Isp + ")"
+}
+
+const (
+	DiagnoseDns      = "dns"
+	DiagnoseTcp      = "tcp"
+	DiagnoseTls      = "tls"
+	DiagnoseHttp     = "http"
+	DiagnoseTcp1620  = "tcp 16-20"
+	DiagnoseSiberian = "siberian"
+)
+
+// Steps are sent as soon as they are done; if a layer fails, the next ones are sent as skipped.
+func DiagnoseGochan(ctx context.Context, host string) <-chan DiagnoseStep {
+	layers := []string{DiagnoseDns, DiagnoseTcp, DiagnoseTls, DiagnoseHttp, DiagnoseTcp1620, DiagnoseSiberian}
+	out := make(chan DiagnoseStep, len(layers)) // never blocks, even if nobody reads it anymore
+
+	go func() {
+		defer close(out)
+		send := func(step DiagnoseStep) bool {
+			log.Println("diagnose;", host, step.Layer, step.Err, step.Evidence)
+			out <- step
+			layers = layers[1:]
+
+			// dns interference is bypassed with the trusted DoH answer
+			proceed := step.Err == nil || step.Err == ErrDiagnoseDnsBlocked || step.Err == ErrDiagnoseDnsSpoofed
+			if proceed && ctx.Err() == nil {
+				return true
+			}
+			for _, x := range layers {
+				out <- DiagnoseStep{Layer: x, Err: ErrWebhostSkip}
+			}
+			return false
+		}
+
+		cfg := config.Get().Checkers.Webhost
+		dnsStep, ip := diagnoseDns(ctx, host)
+		if !send(dnsStep) {
+			return
+		}
+
+		opt := WebhostSingleOpt{Ctx: ctx, Ip: ip, Port: config.Get().Checkers.Diagnose.Port, Sni: host, Host: host}
+		if _, err := netip.ParseAddr(host); err == nil {
+			opt.Sni = "" // no sni for ip literals
+		}
+		tlsConnOpt := inetutil.TlsConnOpt{
+			Ctx:                 ctx,
+			Ip:                  opt.Ip,
+			Port:                opt.Port,
+			Sni:                 opt.Sni,
+			TcpConnTimeout:      cfg.TcpConnTimeout,
+			TcpWriteBuf:         cfg.TcpWriteBuf,
+			TcpReadBuf:          cfg.TcpReadBuf,
+			TlsHandshakeTimeout: cfg.TlsHandshakeTimeout,
+		}
+
+		if !send(diagnoseTcp(opt)) {
+			return
+		}
+		tlsStep, tlsConn := diagnoseTls(opt, tlsConnOpt)
+		if !send(tlsStep) {
+			return
+		}
+		if !send(diagnoseHttp(opt, tlsConn)) {
+			return
+		}
+		if !send(diagnoseTcp1620(opt, tlsConnOpt)) {
+			return
+		}
+		send(diagnoseSiberian(tlsConnOpt))
+	}()
+
+	return out
+}
+
+// Returns the first layer that is interfered with, or nil.
+func DiagnoseRootCause(steps []DiagnoseStep) *DiagnoseStep {
+	for i := range steps {
+		if steps[i].Err != nil && steps[i].Err != ErrWebhostSkip {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+// Compares the system resolver with the trusted DoH; returns the ip for the next layers (preferably from DoH).
+func diagnoseDns(ctx context.Context, host string) (DiagnoseStep, netip.Addr) {
+	step := DiagnoseStep{Layer: DiagnoseDns}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		step.Evidence = append(step.Evidence, "host is an ip literal, nothing to resolve")
+		return step, ip
+	}
+
+	dnsCfg := config.Get().Checkers.Dns.Resolve
+	cfg := config.Get().Checkers.Diagnose
+
+	sysCtx, cancel := context.WithTimeout(ctx, dnsCfg.PlainOpt.Timeout)
+	sysIps, sysErr := net.DefaultResolver.LookupNetIP(sysCtx, "ip4", host)
+	cancel()
+	for i := range sysIps {
+		sysIps[i] = sysIps[i].Unmap()
+	}
+	if sysErr == nil && len(sysIps) == 0 {
+		sysErr = ErrDiagnoseDnsUnresolved
+	}
+	step.Evidence = append(step.Evidence, diagnoseDnsEvidence("system resolver", sysIps, sysErr))
+
+	var dohIps []netip.Addr
+	dohErr := ErrDiagnoseDnsUnresolved
+	if query, err := dnsDohPrepareA(host); err == nil {
+		for _, s := range cfg.Doh.Ips {
+			resolverIp, err := netip.ParseAddr(s)
+			if err != nil {
+				log.Println("diagnose/doh; invalid ip:", s)
+				continue
+			}
+
+			dohCtx, cancel := context.WithTimeout(ctx, dnsCfg.DohOpt.Timeout)
+			resp, err := dnsDohExchange(dohCtx, cfg.Doh.Host, resolverIp, query)
+			cancel()
+			if err == nil {
+				_, dohIps, err = dnsParseA(resp)
+			}
+			if err == nil && len(dohIps) == 0 {
+				err = ErrDiagnoseDnsUnresolved
+			}
+			step.Evidence = append(step.Evidence, diagnoseDnsEvidence(fmt.Sprintf("DoH %s (%s)", cfg.Doh.Host, resolverIp), dohIps, err))
+			if dohErr = err; err == nil {
+				break
+			}
+		}
+	}
+
+	switch {
+	case sysErr != nil && dohErr != nil:
+		step.Err = ErrDiagnoseDnsUnresolved
+		return step, netip.Addr{}
+	case dohErr != nil:
+		step.Evidence = append(step.Evidence, "trusted DoH is unavailable, the system answer can't be verified")
+		return step, sysIps[0]
+	case sysErr != nil:
+		step.Err = ErrDiagnoseDnsBlocked
+	case !diagnoseDnsMatch(sysIps, dohIps):
+		step.Err = ErrDiagnoseDnsSpoofed
+	}
+	return step, dohIps[0]
+}
+
+// Answers match if they have common ips or common AS (e.g. cdn nodes may differ); private ips never match.
+func diagnoseDnsMatch(sysIps, dohIps []netip.Addr) bool {
+	asns := map[int32]struct{}{}
+	for _, ip := range dohIps {
+		if asn := inetlookup.Default().IpInfo(ip).Asn; asn != 0 {
+			asns[asn] = struct{}{}
+		}
+	}
+
+	for _, ip := range sysIps {
+		if ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() {
+			return false
+		}
+	}
+	for _, ip := range sysIps {
+		if slices.Contains(dohIps, ip) {
+			return true
+		}
+		if _, ok := asns[inetlookup.Default().IpInfo(ip).Asn]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func diagnoseDnsEvidence(resolver string, ips []netip.Addr, err error) string {
+	if err != nil {
+		return fmt.Sprintf("%s: %v", resolver, err)
+	}
+
+	s := resolver + ":"
+	for _, ip := range ips {
+		info := inetlookup.Default().IpInfo(ip)
+		s += fmt.Sprintf(" %s (AS%d %s)", ip, info.Asn, info.Org)
+	}
+	return s
+}
+
+func diagnoseTcp(opt WebhostSingleOpt) DiagnoseStep {
+	cfg := config.Get().Checkers.Webhost
+	step := DiagnoseStep{Layer: DiagnoseTcp}
+
+	start := time.Now()
+	tcpConn, err := inetutil.GetTcpConn(inetutil.TcpConnOpt{
+		Ctx:            opt.Ctx,
+		Ip:             opt.Ip,
+		Port:           opt.Port,
+		TcpConnTimeout: cfg.TcpConnTimeout,
+	})
+	addr := netip.AddrPortFrom(opt.Ip, uint16(opt.Port))
+	if err != nil {
+		step.Err = ErrDiagnoseTcp
+		step.Evidence = append(step.Evidence, fmt.Sprintf("connect to %s: %v", addr, err))
+		return step
+	}
+	tcpConn.Close()
+
+	step.Evidence = append(step.Evidence, fmt.Sprintf("connect to %s: ok in %s", addr, time.Since(start).Round(time.Millisecond)))
+	return step
+}
+
+// Returns the handshaked tls connection (with the real sni) if there is no interference.
+func diagnoseTls(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) (DiagnoseStep, *tls.UConn) {
+	step := DiagnoseStep{Layer: DiagnoseTls}
+
+	tlsConn, err := webhostHandshakesCheck(opt, tlsConnOpt)
+	matrix := webhostSniMatrix(opt, tlsConnOpt)
+	for _, x := range matrix {
+		sni := x.Sni
+		if sni == "" {
+			sni = "<none>"
+		}
+		status := "ok"
+		if x.Err != nil {
+			status = x.Err.Error()
+		}
+		step.Evidence = append(step.Evidence, fmt.Sprintf("handshake with %s sni %s: %s", x.Kind, sni, status))
+	}
+
+	if err == nil {
+		return step, tlsConn
+	}
+	if tlsConn != nil {
+		tlsConn.Close() // with a fallback sni
+	}
+
+	step.Err = ErrDiagnoseTls
+	if err == ErrWebhostBlockedBySni || slices.ContainsFunc(matrix, func(x WebhostSniMatrixItem) bool {
+		return x.Kind != SniMatrixOriginal && x.Err == nil
+	}) {
+		step.Err = ErrDiagnoseTlsSni
+	}
+	return step, nil
+}
+
+func diagnoseHttp(opt WebhostSingleOpt, tlsConn *tls.UConn) DiagnoseStep {
+	step := DiagnoseStep{Layer: DiagnoseHttp}
+
+	resp, err := webhostAliveCheck(opt, tlsConn)
+	blockpage, isBlockpage := errors.AsType[*WebhostBlockpageError](err)
+	switch {
+	case err == nil:
+		step.Evidence = append(step.Evidence, fmt.Sprintf("GET request: response is received (status %d)", resp.Status))
+	case err == inetutil.ErrHttpMalformedResponse:
+		step.Evidence = append(step.Evidence, "GET request: non-http response is received (it's fine for non-web hosts)")
+	default:
+		step.Err = ErrDiagnoseHttp
+		if isBlockpage {
+			step.Err = &DiagnoseBlockpageError{Isp: blockpage.Isp}
+		}
+		step.Evidence = append(step.Evidence, fmt.Sprintf("GET request: %v", err))
+		if resp != nil && resp.Location != "" {
+			step.Evidence = append(step.Evidence, fmt.Sprintf("status %d, location: %s", resp.Status, resp.Location))
+		}
+	}
+	return step
+}
+
+func diagnoseTcp1620(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) DiagnoseStep {
+	cfg := config.Get().Checkers.Webhost
+	step := DiagnoseStep{Layer: DiagnoseTcp1620}
+	const bytesToKilobits = 8.0 / 1_000
+
+	thp, upErr := webhostTcp1620check(opt, tlsConnOpt, cfg.Tcp1620nBytes)
+	if upErr == nil {
+		step.Evidence = append(step.Evidence, fmt.Sprintf("upload of %d bytes: ok (%.1f kb/s)",
+			thp.TxBytes, float64(thp.TxBytes)/thp.TxElapsed.Seconds()*bytesToKilobits))
+	} else {
+		step.Evidence = append(step.Evidence, fmt.Sprintf("upload of %d bytes: %v", cfg.Tcp1620nBytes, upErr))
+	}
+
+	thp, downErr := webhostTcp1620DownCheck(opt, tlsConnOpt)
+	if downErr == nil {
+		step.Evidence = append(step.Evidence, fmt.Sprintf("download of %d bytes: ok (%.1f kb/s)",
+			thp.RxBytes, float64(thp.RxBytes)/thp.RxElapsed.Seconds()*bytesToKilobits))
+	} else {
+		step.Evidence = append(step.Evidence, fmt.Sprintf("download of %d bytes: %v", cfg.Tcp1620DownNBytes, downErr))
+	}
+
+	if webhostTcp1620detected(upErr) || webhostTcp1620detected(downErr) {
+		step.Err = ErrDiagnoseTcp1620
+	}
+	return step
+}
+
+func diagnoseSiberian(tlsConnOpt inetutil.TlsConnOpt) DiagnoseStep {
+	step := DiagnoseStep{Layer: DiagnoseSiberian}
+	if err := webhostSiberianCheck(tlsConnOpt); err != nil {
+		step.Err = ErrDiagnoseSiberian
+		step.Evidence = append(step.Evidence, fmt.Sprintf("%d handshakes in a row, then a new one: %v",
+			config.Get().Checkers.Webhost.SiberianConnCount, err))
+		return step
+	}
+	step.Evidence = append(step.Evidence, "handshakes in a row: ok")
+	return step
+}
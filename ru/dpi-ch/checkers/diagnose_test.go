@@ -0,0 +1,42 @@
+package checkers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+func TestDiagnoseHttp(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://warning.rt.ru/", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	diagnose := func(path string) DiagnoseStep {
+		tlsConn, err := inetutil.GetHandshakedUTlsConn(inetutil.TlsConnOpt{Ip: addr.Addr(), Port: int(addr.Port()), Sni: "example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diagnoseHttp(WebhostSingleOpt{Ip: addr.Addr(), Host: "example.com" + path}, tlsConn)
+	}
+
+	if step := diagnose("/"); step.Err != nil {
+		t.Fatalf("ok: got %v", step.Err)
+	}
+	step := diagnose("/redirect")
+	if blockpage, ok := errors.AsType[*DiagnoseBlockpageError](step.Err); !ok || blockpage.Isp != "Rostelecom" {
+		t.Fatalf("redirect: got %v, want Rostelecom stub page", step.Err)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"log"
 	"net"
 	"strings"
+	"time"
 
 	rand "math/rand/v2"
 	"net/http"
@@ -22,7 +24,7 @@ import (
 )
 
 type DnsPlainProvider struct {
-	Addrs []string // ip:port (udp)
+	Addrs []string // ip:port (both udp and tcp)
 }
 
 type DnsDohProvider struct {
@@ -30,18 +32,35 @@ type DnsDohProvider struct {
 	Filter string   // subnetfilter for DoH bootstrap spoofing check
 }
 
+type DnsDotProvider struct {
+	Hosts  []string // RFC 7858: DoT + port 853 by default
+	Filter string   // subnetfilter for DoT bootstrap spoofing check
+}
+
+type DnsDoqProvider struct {
+	Hosts  []string // RFC 9250: DoQ + udp port 853 by default
+	Filter string   // subnetfilter for DoQ bootstrap spoofing check
+}
+
+type DnsDoh3Provider struct {
+	Hosts  []string // RFC 8484 over http/3 (RFC 9114)
+	Filter string   // subnetfilter for DoH3 bootstrap spoofing check
+}
+
 type DnsTarget struct {
 	Hostname string // target for receiving an A record
-	Filter   string // subnetfilter for response spoofing check (relevant for plain mode)
+	Filter   string // subnetfilter for response spoofing check (both plain and DoH modes)
 }
 
 type DnsPlainAnswer struct {
 	Target       DnsTarget
-	ResolverAddr string // ip:port (udp)
+	ResolverAddr string // ip:port
+	Network      string // udp or tcp
 	Items        []netip.Addr
 	Err          error
 }
 
+// Also used for DoT, DoQ and DoH3.
 type DnsDohAnswer struct {
 	Target           DnsTarget
 	ResolverHostname string
@@ -51,6 +70,7 @@ type DnsDohAnswer struct {
 
 type DnsDohAnswerItem struct {
 	ResolverIp netip.Addr
+	Items      []netip.Addr
 	Err        error
 }
 
@@ -59,6 +79,13 @@ type DnsVerdict struct {
 	Verdict  error
 }
 
+// Plain mode verdicts of the provider, by transport.
+type DnsPlainVerdict struct {
+	Provider string
+	Udp      error // ErrDnsUdpInjection if udp answers are spoofed while tcp ones are not
+	Tcp      error
+}
+
 type DnsLeakWithIpinfoOut struct {
 	Items []inetlookup.IpInfoStrings
 	Err   error
@@ -74,24 +101,68 @@ var (
 	ErrDnsSkip                 = errors.New("dns: skip")
 	ErrDnsResolveSpoofing      = errors.New("dns: response spoofing")
 	ErrDnsNxdomainSpoofing     = errors.New("dns: nxdomain spoofing")
+	ErrDnsUdpInjection         = errors.New("dns: udp injection (udp and tcp answers differ)")
 	ErrDnsDohBootstrapSpoofing = errors.New("dns: doh bootstrap spoofing")
 	ErrDnsDohBootstrapEmpty    = errors.New("dns: doh bootstrap empty")
 	ErrDnsDohInsecure          = errors.New("dns: doh insecure")
 	ErrDnsDohNon2xxResp        = errors.New("dns: doh non-2xx response")
+	ErrDnsDotInsecure          = errors.New("dns: dot insecure")
+	ErrDnsDoqInsecure          = errors.New("dns: doq insecure")
+	ErrDnsDoh3Insecure         = errors.New("dns: doh3 insecure")
+	ErrDnsServfail             = errors.New("dns: servfail")
+	ErrDnsRefused              = errors.New("dns: refused")
+	ErrDnsRcode                = errors.New("dns: unexpected rcode")
+	ErrDnsEmptyAnswer          = errors.New("dns: empty answer")
+	ErrDnsMalformed            = errors.New("dns: malformed response")
 )
 
+// Sends a wire format dns query to the resolver (e.g. DoH or DoT); returns the raw dns response.
+type dnsExchangeFunc func(ctx context.Context, resolverHostname string, resolverIp netip.Addr, query []byte) ([]byte, error)
+
 // Resolve in DoH mode + spoofing check; bsProvider is used for the DoH bootstrap.
 func dnsDohMatrix(ctx context.Context, bsProvider DnsPlainProvider, dohProvider DnsDohProvider, targets []DnsTarget) []DnsDohAnswer {
+	timeout := config.Get().Checkers.Dns.Resolve.DohOpt.Timeout
+	return dnsResolverMatrix(ctx, bsProvider, dohProvider.Hosts, dohProvider.Filter, targets, timeout, dnsDohExchange)
+}
+
+// Resolve in DoT mode + spoofing check; bsProvider is used for the DoT bootstrap.
+func dnsDotMatrix(ctx context.Context, bsProvider DnsPlainProvider, dotProvider DnsDotProvider, targets []DnsTarget) []DnsDohAnswer {
+	timeout := config.Get().Checkers.Dns.Resolve.DotOpt.Timeout
+	return dnsResolverMatrix(ctx, bsProvider, dotProvider.Hosts, dotProvider.Filter, targets, timeout, dnsDotExchange)
+}
+
+// Resolve in DoQ mode + spoofing check; bsProvider is used for the DoQ bootstrap.
+func dnsDoqMatrix(ctx context.Context, bsProvider DnsPlainProvider, doqProvider DnsDoqProvider, targets []DnsTarget) []DnsDohAnswer {
+	timeout := config.Get().Checkers.Dns.Resolve.DoqOpt.Timeout
+	return dnsResolverMatrix(ctx, bsProvider, doqProvider.Hosts, doqProvider.Filter, targets, timeout, dnsDoqExchange)
+}
+
+// Resolve in DoH3 mode + spoofing check; bsProvider is used for the DoH3 bootstrap.
+func dnsDoh3Matrix(ctx context.Context, bsProvider DnsPlainProvider, doh3Provider DnsDoh3Provider, targets []DnsTarget) []DnsDohAnswer {
+	timeout := config.Get().Checkers.Dns.Resolve.Doh3Opt.Timeout
+	return dnsResolverMatrix(ctx, bsProvider, doh3Provider.Hosts, doh3Provider.Filter, targets, timeout, dnsDoh3Exchange)
+}
+
+// Resolves the targets with every ip of every resolver host (hosts are bootstrapped by bsProvider and checked by filter).
+func dnsResolverMatrix(
+	ctx context.Context,
+	bsProvider DnsPlainProvider,
+	hosts []string,
+	filter string,
+	targets []DnsTarget,
+	timeout time.Duration,
+	exchange dnsExchangeFunc,
+) []DnsDohAnswer {
 	res := []DnsDohAnswer{}
 
 	bootstraps := map[string][]DnsPlainAnswer{}
-	for _, host := range dohProvider.Hosts {
-		target := []DnsTarget{{Hostname: host, Filter: dohProvider.Filter}}
-		bootstraps[host] = dnsPlainMatrix(ctx, bsProvider, target)
+	for _, host := range hosts {
+		target := []DnsTarget{{Hostname: host, Filter: filter}}
+		bootstraps[host] = dnsPlainMatrix(ctx, bsProvider, "udp", target)
 	}
 
 	for _, target := range targets {
-		for _, host := range dohProvider.Hosts {
+		for _, host := range hosts {
 			ans := DnsDohAnswer{Target: target, ResolverHostname: host, Items: []DnsDohAnswerItem{}}
 			hostBootstrap := bootstraps[host]
 
@@ -114,7 +185,7 @@ func dnsDohMatrix(ctx context.Context, bsProvider DnsPlainProvider, dohProvider
 				}
 
 				for ip := range hostIps {
-					item := dnsDohRaw(ctx, target, host, ip)
+					item := dnsResolverRaw(ctx, target, host, ip, timeout, exchange)
 					ans.Items = append(ans.Items, item)
 				}
 			}()
@@ -126,15 +197,80 @@ func dnsDohMatrix(ctx context.Context, bsProvider DnsPlainProvider, dohProvider
 	return res
 }
 
-func dnsDohRaw(ctx context.Context, target DnsTarget, resolverHostname string, resolverIp netip.Addr) DnsDohAnswerItem {
-	cfg := config.Get().Checkers.Dns.Resolve
+func dnsResolverRaw(
+	ctx context.Context,
+	target DnsTarget,
+	resolverHostname string,
+	resolverIp netip.Addr,
+	timeout time.Duration,
+	exchange dnsExchangeFunc,
+) DnsDohAnswerItem {
 	res := DnsDohAnswerItem{ResolverIp: resolverIp}
 
-	innerCtx, cancel := context.WithTimeout(ctx, cfg.DohOpt.Timeout)
+	innerCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	preparedA, err := dnsDohPrepareA(target.Hostname)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	resp, err := exchange(innerCtx, resolverHostname, resolverIp, preparedA)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	h, ips, err := dnsParseA(resp)
+	if err != nil {
+		log.Println("dnsResolverRaw", resolverHostname, resolverIp, err)
+		res.Err = ErrDnsMalformed
+		return res
+	}
+	res.Items = ips
+	if res.Err = dnsRcodeVerdict(h.RCode); res.Err != nil {
+		return res
+	}
+	if len(ips) == 0 {
+		res.Err = ErrDnsEmptyAnswer
+		return res
+	}
+
+	orig, err := subnetfilterMatchAll(ips, target.Filter)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if !orig {
+		res.Err = ErrDnsResolveSpoofing
+		log.Println("dnsResolverRaw", "response spoofing", resolverHostname, resolverIp, target.Hostname, ips)
+	}
+	return res
+}
+
+// The targets are known to exist, so nxdomain is considered as spoofing.
+func dnsRcodeVerdict(rcode dnsmessage.RCode) error {
+	switch rcode {
+	case dnsmessage.RCodeSuccess:
+		return nil
+	case dnsmessage.RCodeNameError:
+		return ErrDnsNxdomainSpoofing
+	case dnsmessage.RCodeServerFailure:
+		return ErrDnsServfail
+	case dnsmessage.RCodeRefused:
+		return ErrDnsRefused
+	default:
+		return ErrDnsRcode
+	}
+}
+
+// Sends a wire format dns query to the DoH resolver (over a new tls connection); returns the raw dns response.
+func dnsDohExchange(ctx context.Context, resolverHostname string, resolverIp netip.Addr, query []byte) ([]byte, error) {
+	cfg := config.Get().Checkers.Dns.Resolve
+
 	tlsConnOpt := inetutil.TlsConnOpt{
-		Ctx:            innerCtx,
+		Ctx:            ctx,
 		Ip:             resolverIp,
 		Port:           443, // TODO: config that
 		Sni:            resolverHostname,
@@ -143,48 +279,126 @@ func dnsDohRaw(ctx context.Context, target DnsTarget, resolverHostname string, r
 
 	tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
 	if err != nil {
-		res.Err = err
 		if err == inetutil.ErrTlsCertificateInvalid {
-			res.Err = ErrDnsDohInsecure
+			return nil, ErrDnsDohInsecure
 		}
-
-		return res
+		return nil, err
 	}
 	defer tlsConn.Close()
 
-	preparedA, err := dnsDohPrepareA(target.Hostname)
+	req, err := http.NewRequest("POST", "https://"+resolverHostname+cfg.DohOpt.Path, bytes.NewReader(query))
 	if err != nil {
-		res.Err = err
-		return res
-	}
-
-	req, err := http.NewRequest("POST", "https://"+resolverHostname+cfg.DohOpt.Path, bytes.NewReader(preparedA))
-	if err != nil {
-		res.Err = err
-		return res
+		return nil, err
 	}
 	req.Close = true // TODO: it is better to keep one connection open to each resolver
 	req.Header.Set("Content-Type", "application/dns-message")
 
 	inetutil.SetHeaders(&req.Header, cfg.DohOpt.HttpStaticHeaders)
 
-	if _, err := inetutil.TlsWriteHttpRequest(innerCtx, tlsConn, req); err != nil {
-		res.Err = err
-		return res
+	if _, err := inetutil.TlsWriteHttpRequest(ctx, tlsConn, req); err != nil {
+		return nil, err
 	}
 
-	resp, err := inetutil.TlsReadHttpResponse(innerCtx, tlsConn, bufio.NewReader(tlsConn))
+	resp, err := inetutil.TlsReadHttpResponse(ctx, tlsConn, bufio.NewReader(tlsConn))
 	if err != nil {
-		res.Err = err
-		return res
+		return nil, err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		res.Err = ErrDnsDohNon2xxResp
+		return nil, ErrDnsDohNon2xxResp
 	}
 
-	return res
+	var body bytes.Buffer
+	const maxDnsMsgLen = 65535
+	if _, err := inetutil.TlsReadHttpBody(ctx, tlsConn, io.TeeReader(io.LimitReader(resp.Body, maxDnsMsgLen), &body)); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// Sends a wire format dns query to the DoT resolver (over a new tls connection); returns the raw dns response.
+func dnsDotExchange(ctx context.Context, resolverHostname string, resolverIp netip.Addr, query []byte) ([]byte, error) {
+	cfg := config.Get().Checkers.Dns.Resolve
+
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(inetutil.TlsConnOpt{
+		Ctx:            ctx,
+		Ip:             resolverIp,
+		Port:           cfg.DotOpt.Port,
+		Sni:            resolverHostname,
+		InsecureVerify: true,
+		Alpn:           []string{"dot"},
+	})
+	if err != nil {
+		if err == inetutil.ErrTlsCertificateInvalid {
+			return nil, ErrDnsDotInsecure
+		}
+		return nil, err
+	}
+	defer tlsConn.Close()
+
+	return inetutil.TcpDnsExchange(ctx, tlsConn, query)
+}
+
+// Sends a wire format dns query to the DoQ resolver (over a new quic connection); returns the raw dns response.
+func dnsDoqExchange(ctx context.Context, resolverHostname string, resolverIp netip.Addr, query []byte) ([]byte, error) {
+	cfg := config.Get().Checkers.Dns.Resolve
+
+	quicConn, err := inetutil.GetHandshakedQuicConn(inetutil.QuicConnOpt{
+		Ctx:            ctx,
+		Ip:             resolverIp,
+		Port:           cfg.DoqOpt.Port,
+		Sni:            resolverHostname,
+		Alpn:           []string{"doq"},
+		InsecureVerify: true,
+	})
+	if err != nil {
+		if err == inetutil.ErrTlsCertificateInvalid {
+			return nil, ErrDnsDoqInsecure
+		}
+		return nil, err
+	}
+	defer quicConn.Close()
+
+	return inetutil.QuicDnsExchange(ctx, quicConn, query)
+}
+
+// Sends a wire format dns query to the DoH3 resolver (over a new quic connection); returns the raw dns response.
+func dnsDoh3Exchange(ctx context.Context, resolverHostname string, resolverIp netip.Addr, query []byte) ([]byte, error) {
+	cfg := config.Get().Checkers.Dns.Resolve
+
+	quicConn, err := inetutil.GetHandshakedQuicConn(inetutil.QuicConnOpt{
+		Ctx:            ctx,
+		Ip:             resolverIp,
+		Port:           443,
+		Sni:            resolverHostname,
+		InsecureVerify: true,
+	})
+	if err != nil {
+		if err == inetutil.ErrTlsCertificateInvalid {
+			return nil, ErrDnsDoh3Insecure
+		}
+		return nil, err
+	}
+	defer quicConn.Close()
+
+	req, err := http.NewRequest("POST", "https://"+resolverHostname+cfg.DohOpt.Path, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+
+	inetutil.SetHeaders(&req.Header, cfg.DohOpt.HttpStaticHeaders)
+
+	const maxDnsMsgLen = 65535
+	resp, body, err := inetutil.QuicHttp3Request(ctx, quicConn, req, maxDnsMsgLen)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, ErrDnsDohNon2xxResp
+	}
+	return body, nil
 }
 
 func dnsPlainVerdict(matrix []DnsPlainAnswer) error {
@@ -226,8 +440,24 @@ func dnsDohVerdict(matrix []DnsDohAnswer) error {
 	return err
 }
 
+// Injectors usually spoof udp only. Answers of the same resolver may legitimately differ in ips (e.g. cdn rotation),
+// so they are compared by the spoofing check against the target filter rather than by the ips themselves.
+// The matrices must be made for the same provider and targets.
+func dnsPlainInjected(udp, tcp []DnsPlainAnswer) bool {
+	for i := range min(len(udp), len(tcp)) {
+		spoofed := udp[i].Err == ErrDnsResolveSpoofing || udp[i].Err == ErrDnsNxdomainSpoofing
+		if spoofed && tcp[i].Err == nil {
+			log.Println("dnsPlainInjected", udp[i], tcp[i])
+			return true
+		}
+	}
+	return false
+}
+
 func plainErrImportance(err error) int {
 	switch err {
+	case ErrDnsUdpInjection:
+		return 4
 	case ErrDnsResolveSpoofing:
 		return 3
 	case ErrDnsNxdomainSpoofing:
@@ -245,10 +475,12 @@ func dohErrImportance(err error) int {
 	}
 	switch err {
 	case ErrDnsDohBootstrapSpoofing:
+		return 6
+	case ErrDnsResolveSpoofing:
 		return 5
-	case ErrDnsDohInsecure:
+	case ErrDnsDohInsecure, ErrDnsDotInsecure, ErrDnsDoqInsecure, ErrDnsDoh3Insecure, ErrDnsNxdomainSpoofing:
 		return 4
-	case ErrDnsDohNon2xxResp:
+	case ErrDnsDohNon2xxResp, ErrDnsServfail, ErrDnsRefused, ErrDnsRcode, ErrDnsEmptyAnswer, ErrDnsMalformed:
 		return 2
 	case nil:
 		return 0
@@ -257,16 +489,16 @@ func dohErrImportance(err error) int {
 	}
 }
 
-// Resolve in plain mode + spoofing check.
-func dnsPlainMatrix(ctx context.Context, provider DnsPlainProvider, targets []DnsTarget) []DnsPlainAnswer {
+// Resolve in plain mode over the given network only (udp or tcp) + spoofing check.
+func dnsPlainMatrix(ctx context.Context, provider DnsPlainProvider, network string, targets []DnsTarget) []DnsPlainAnswer {
 	res := []DnsPlainAnswer{}
 
 	for _, target := range targets {
 		for _, addr := range provider.Addrs {
-			item := DnsPlainAnswer{Target: target, ResolverAddr: addr}
+			item := DnsPlainAnswer{Target: target, ResolverAddr: addr, Network: network}
 
 			func() {
-				ips, err := dnsPlainA(ctx, addr, target.Hostname)
+				ips, err := dnsPlainA(ctx, network, addr, target.Hostname)
 				if err != nil {
 					if dnsErr, ok := errors.AsType[*net.DNSError](err); ok {
 						if dnsErr.IsNotFound {
@@ -359,6 +591,10 @@ func subnetfilterMatchAll(ips []netip.Addr, filter string) (bool, error) {
 }
 
 func dnsDohPrepareA(target string) ([]byte, error) {
+	return dnsDohPrepare(target, dnsmessage.TypeA)
+}
+
+func dnsDohPrepare(target string, qtype dnsmessage.Type) ([]byte, error) {
 	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
 		RecursionDesired: true,
 	})
@@ -375,7 +611,7 @@ func dnsDohPrepareA(target string) ([]byte, error) {
 
 	err = b.Question(dnsmessage.Question{
 		Name:  dnsmessage.MustNewName(target),
-		Type:  dnsmessage.TypeA,
+		Type:  qtype,
 		Class: dnsmessage.ClassINET,
 	})
 	if err != nil {
@@ -385,8 +621,64 @@ func dnsDohPrepareA(target string) ([]byte, error) {
 	return b.Finish()
 }
 
+type dnsARecord struct {
+	Ip  netip.Addr
+	Ttl uint32
+}
+
+// Returns the header (incl. rcode) and A records from the wire format dns response.
+func dnsParseA(msg []byte) (dnsmessage.Header, []netip.Addr, error) {
+	header, records, err := dnsParseARecords(msg)
+	if err != nil {
+		return header, nil, err
+	}
+
+	out := make([]netip.Addr, 0, len(records))
+	for _, r := range records {
+		out = append(out, r.Ip)
+	}
+	return header, out, nil
+}
+
+// Like dnsParseA, but the records come with their ttls.
+func dnsParseARecords(msg []byte) (dnsmessage.Header, []dnsARecord, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil {
+		return header, nil, err
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return header, nil, err
+	}
+
+	out := []dnsARecord{}
+	for {
+		h, err := p.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			return header, out, nil
+		}
+		if err != nil {
+			return header, nil, err
+		}
+		if h.Type != dnsmessage.TypeA {
+			if err := p.SkipAnswer(); err != nil {
+				return header, nil, err
+			}
+			continue
+		}
+
+		r, err := p.AResource()
+		if err != nil {
+			return header, nil, err
+		}
+		out = append(out, dnsARecord{Ip: netip.AddrFrom4(r.A), Ttl: h.TTL})
+	}
+}
+
 // Resolves A records for the specified hostname using the specified DNS server.
-func dnsPlainA(ctx context.Context, addr, target string) ([]netip.Addr, error) {
+// The go resolver picks the transport itself (udp, then tcp on truncation), so the network is forced by the dialer;
+// the resolver frames the messages by the type of the returned conn.
+func dnsPlainA(ctx context.Context, network, addr, target string) ([]netip.Addr, error) {
 	cfg := config.Get().Checkers.Dns.Resolve
 	ctx, cancel := context.WithTimeout(ctx, cfg.PlainOpt.Timeout)
 	defer cancel()
@@ -395,7 +687,7 @@ func dnsPlainA(ctx context.Context, addr, target string) ([]netip.Addr, error) {
 		PreferGo: true,
 		Dial: func(_ctx context.Context, _network, _ string) (net.Conn, error) {
 			d := &net.Dialer{}
-			return d.DialContext(_ctx, _network, addr)
+			return d.DialContext(_ctx, network, addr)
 		},
 	}
 
@@ -22,26 +22,34 @@ type DnsDohGochanIn struct {
 	Targets           []DnsTarget
 }
 
-func DnsPlainGochan(ctx context.Context) <-chan DnsVerdict {
+// Every provider is checked over udp only and over tcp only.
+func DnsPlainGochan(ctx context.Context) <-chan DnsPlainVerdict {
 	cfg := config.Get().Checkers.Dns.Resolve
 	in := make(chan DnsPlainGochanIn)
-	out := gochan.Start(gochan.GochanOpt[DnsPlainGochanIn, DnsVerdict]{
+	out := gochan.Start(gochan.GochanOpt[DnsPlainGochanIn, DnsPlainVerdict]{
 		Ctx:     ctx,
 		Workers: cfg.PlainOpt.Workers,
 		Input:   in,
-		Executor: func(in DnsPlainGochanIn) DnsVerdict {
+		Executor: func(in DnsPlainGochanIn) DnsPlainVerdict {
 			if len(in.Provider.Addrs) == 0 {
-				return DnsVerdict{
+				return DnsPlainVerdict{
 					Provider: in.Id,
-					Verdict:  ErrDnsSkip,
+					Udp:      ErrDnsSkip,
+					Tcp:      ErrDnsSkip,
 				}
 			}
 
-			matrix := dnsPlainMatrix(in.Ctx, in.Provider, in.Targets)
-			return DnsVerdict{
+			udp := dnsPlainMatrix(in.Ctx, in.Provider, "udp", in.Targets)
+			tcp := dnsPlainMatrix(in.Ctx, in.Provider, "tcp", in.Targets)
+			res := DnsPlainVerdict{
 				Provider: in.Id,
-				Verdict:  dnsPlainVerdict(matrix),
+				Udp:      dnsPlainVerdict(udp),
+				Tcp:      dnsPlainVerdict(tcp),
+			}
+			if dnsPlainInjected(udp, tcp) {
+				res.Udp = ErrDnsUdpInjection
 			}
+			return res
 		},
 	})
 
@@ -97,6 +105,82 @@ func DnsDohGochan(ctx context.Context) <-chan DnsVerdict {
 	return out
 }
 
+// Encrypted transports (except the original DoH) differ only in the provider and the matrix.
+type dnsEncryptedGochanIn[P any] struct {
+	Id                string
+	Ctx               context.Context
+	BootstrapProvider DnsPlainProvider
+	Provider          P
+	Skip              bool // the provider has no hosts for the transport
+	Targets           []DnsTarget
+}
+
+type dnsEncryptedMatrixFunc[P any] func(ctx context.Context, bsProvider DnsPlainProvider, provider P, targets []DnsTarget) []DnsDohAnswer
+
+// Provider returns the transport provider of the i-th config provider and false if it has no hosts.
+func dnsEncryptedGochan[P any](ctx context.Context, workers int, matrix dnsEncryptedMatrixFunc[P], provider func(i int) (P, bool)) <-chan DnsVerdict {
+	cfg := config.Get().Checkers.Dns.Resolve
+	in := make(chan dnsEncryptedGochanIn[P])
+	out := gochan.Start(gochan.GochanOpt[dnsEncryptedGochanIn[P], DnsVerdict]{
+		Ctx:     ctx,
+		Workers: workers,
+		Input:   in,
+		Executor: func(in dnsEncryptedGochanIn[P]) DnsVerdict {
+			if in.Skip {
+				return DnsVerdict{
+					Provider: in.Id,
+					Verdict:  ErrDnsSkip,
+				}
+			}
+
+			return DnsVerdict{
+				Provider: in.Id,
+				Verdict:  dnsDohVerdict(matrix(in.Ctx, in.BootstrapProvider, in.Provider, in.Targets)),
+			}
+		},
+	})
+
+	items := []dnsEncryptedGochanIn[P]{}
+	for i, p := range cfg.Providers {
+		x, ok := provider(i)
+		items = append(items, dnsEncryptedGochanIn[P]{
+			Id:                p.Name,
+			Ctx:               ctx,
+			BootstrapProvider: DnsPlainProvider{Addrs: p.Plain},
+			Provider:          x,
+			Skip:              !ok,
+			Targets:           dnsTargets(),
+		})
+	}
+
+	gochan.Push(ctx, in, items)
+	return out
+}
+
+func DnsDotGochan(ctx context.Context) <-chan DnsVerdict {
+	cfg := config.Get().Checkers.Dns.Resolve
+	return dnsEncryptedGochan(ctx, cfg.DotOpt.Workers, dnsDotMatrix, func(i int) (DnsDotProvider, bool) {
+		p := cfg.Providers[i].DoT
+		return DnsDotProvider{Hosts: p.Hosts, Filter: p.Filter}, len(p.Hosts) > 0
+	})
+}
+
+func DnsDoqGochan(ctx context.Context) <-chan DnsVerdict {
+	cfg := config.Get().Checkers.Dns.Resolve
+	return dnsEncryptedGochan(ctx, cfg.DoqOpt.Workers, dnsDoqMatrix, func(i int) (DnsDoqProvider, bool) {
+		p := cfg.Providers[i].DoQ
+		return DnsDoqProvider{Hosts: p.Hosts, Filter: p.Filter}, len(p.Hosts) > 0
+	})
+}
+
+func DnsDoh3Gochan(ctx context.Context) <-chan DnsVerdict {
+	cfg := config.Get().Checkers.Dns.Resolve
+	return dnsEncryptedGochan(ctx, cfg.Doh3Opt.Workers, dnsDoh3Matrix, func(i int) (DnsDoh3Provider, bool) {
+		p := cfg.Providers[i].DoH3
+		return DnsDoh3Provider{Hosts: p.Hosts, Filter: p.Filter}, len(p.Hosts) > 0
+	})
+}
+
 func DnsLeakGochan(ctx context.Context) <-chan DnsLeakWithIpinfoOut {
 	cfg := config.Get().Checkers.Dns.Leak
 
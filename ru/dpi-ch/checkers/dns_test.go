@@ -0,0 +1,120 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Returns a wire format response with the A records and rcode.
+func dnsTestResponse(t *testing.T, rcode dnsmessage.RCode, ips ...string) []byte {
+	t.Helper()
+	name := dnsmessage.MustNewName("example.com.")
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, RCode: rcode})
+	b.StartQuestions()
+	b.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET})
+	b.StartAnswers()
+	hdr := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: 60}
+	b.CNAMEResource(hdr, dnsmessage.CNAMEResource{CNAME: name}) // non-A records are skipped
+	for _, s := range ips {
+		b.AResource(hdr, dnsmessage.AResource{A: netip.MustParseAddr(s).As4()})
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestDnsParseA(t *testing.T) {
+	cases := []struct {
+		msg     []byte
+		ips     []netip.Addr
+		verdict error
+	}{
+		{dnsTestResponse(t, dnsmessage.RCodeSuccess, "1.1.1.1", "1.0.0.1"), []netip.Addr{netip.MustParseAddr("1.1.1.1"), netip.MustParseAddr("1.0.0.1")}, nil},
+		{dnsTestResponse(t, dnsmessage.RCodeNameError), []netip.Addr{}, ErrDnsNxdomainSpoofing},
+		{dnsTestResponse(t, dnsmessage.RCodeServerFailure), []netip.Addr{}, ErrDnsServfail},
+		{dnsTestResponse(t, dnsmessage.RCodeRefused), []netip.Addr{}, ErrDnsRefused},
+		{dnsTestResponse(t, dnsmessage.RCodeNotImplemented), []netip.Addr{}, ErrDnsRcode},
+	}
+	for _, c := range cases {
+		h, ips, err := dnsParseA(c.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ips, c.ips) || dnsRcodeVerdict(h.RCode) != c.verdict {
+			t.Fatalf("rcode %v: got %v, %v; want %v, %v", h.RCode, ips, dnsRcodeVerdict(h.RCode), c.ips, c.verdict)
+		}
+	}
+
+	if _, _, err := dnsParseA([]byte("<html>")); err == nil {
+		t.Fatal("malformed: got nil error")
+	}
+}
+
+// The resolver certificate is verified (unlike webhost checks), so a self-signed one is considered as an interception.
+func TestDnsDotInsecure(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	cert := srv.TLS.Certificates[0]
+	srv.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr).AddrPort()
+	config.Get().Checkers.Dns.Resolve.DotOpt.Port = int(addr.Port())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := dnsDotExchange(ctx, "dns.example.com", addr.Addr(), nil); err != ErrDnsDotInsecure {
+		t.Fatalf("got %v, want %v", err, ErrDnsDotInsecure)
+	}
+}
+
+func TestDnsPlainInjected(t *testing.T) {
+	ans := func(err error) []DnsPlainAnswer { return []DnsPlainAnswer{{Err: err}} }
+	cases := []struct {
+		udp, tcp []DnsPlainAnswer
+		want     bool
+	}{
+		{ans(nil), ans(nil), false},
+		{ans(ErrDnsResolveSpoofing), ans(nil), true},
+		{ans(ErrDnsNxdomainSpoofing), ans(nil), true},
+		{ans(ErrDnsResolveSpoofing), ans(ErrDnsResolveSpoofing), false}, // the resolver itself is spoofing
+		{ans(ErrDnsResolveSpoofing), ans(ErrDnsServfail), false},        // nothing to compare with
+		{ans(ErrDnsServfail), ans(nil), false},
+	}
+	for i, c := range cases {
+		if got := dnsPlainInjected(c.udp, c.tcp); got != c.want {
+			t.Fatalf("case %d: got %v, want %v", i, got, c.want)
+		}
+	}
+}
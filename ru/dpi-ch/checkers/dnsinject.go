@@ -0,0 +1,144 @@
+// Detects on-path dns injection: queries are sent to "silent" ips that run no dns server, so any answer to them
+// is injected by a censor. Control domains (not expected to be censored) are queried first: an answer to them
+// means the ip is not silent (e.g. it runs a resolver), so the sensitive domains are not checked at all.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/netip"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type DnsinjectSingleOpt struct {
+	Ctx context.Context
+	Ip  netip.Addr
+}
+
+type DnsinjectAnswer struct {
+	IpInfo inetlookup.IpInfo
+	Ttl    uint32
+}
+
+type DnsinjectItem struct {
+	Domain  string
+	Rcode   dnsmessage.RCode
+	Answers []DnsinjectAnswer // A records of the answer
+	Err     error             // nil is answered; otherwise inetutil error (e.g. read timeout is silence) or ErrDnsMalformed
+	Verdict error
+}
+
+type DnsinjectSingleResult struct {
+	IpInfo   inetlookup.IpInfo
+	Controls []DnsinjectItem
+	Items    []DnsinjectItem // sensitive domains
+	Verdict  error
+}
+
+var (
+	ErrDnsinjectInjected  = errors.New("dnsinject: injected answer")
+	ErrDnsinjectNotSilent = errors.New("dnsinject: ip is not silent (control domain is answered)")
+)
+
+func DnsinjectSingle(opt DnsinjectSingleOpt) DnsinjectSingleResult {
+	cfg := config.Get().Checkers.Dnsinject
+	res := DnsinjectSingleResult{IpInfo: inetlookup.Default().IpInfo(opt.Ip)}
+
+	for _, domain := range cfg.Control {
+		item, records := dnsinjectQuery(opt.Ctx, opt.Ip, domain)
+		item.Answers = dnsinjectAnswers(records)
+		item.Verdict = dnsinjectVerdict(item.Err, true)
+		if item.Verdict == ErrDnsinjectNotSilent {
+			res.Verdict = ErrDnsinjectNotSilent
+		}
+		res.Controls = append(res.Controls, item)
+	}
+
+	for _, domain := range cfg.Sensitive {
+		item := DnsinjectItem{Domain: domain}
+		switch {
+		case opt.Ctx.Err() != nil:
+			item.Err, item.Verdict = ErrDnsSkip, ErrDnsSkip
+		case res.Verdict == ErrDnsinjectNotSilent:
+			item.Err, item.Verdict = ErrDnsSkip, ErrDnsinjectNotSilent
+		default:
+			var records []dnsARecord
+			item, records = dnsinjectQuery(opt.Ctx, opt.Ip, domain)
+			item.Answers = dnsinjectAnswers(records)
+			item.Verdict = dnsinjectVerdict(item.Err, false)
+			if item.Verdict == ErrDnsinjectInjected {
+				res.Verdict = ErrDnsinjectInjected
+			}
+		}
+		res.Items = append(res.Items, item)
+	}
+
+	log.Println("dnsinject; ip:", opt.Ip, "verdict:", res.Verdict, "controls:", res.Controls, "items:", res.Items)
+	return res
+}
+
+// Sends an A query to the ip and waits for the first answer (the injected one usually comes alone and fast).
+func dnsinjectQuery(ctx context.Context, ip netip.Addr, domain string) (DnsinjectItem, []dnsARecord) {
+	cfg := config.Get().Checkers.Dnsinject
+	item := DnsinjectItem{Domain: domain}
+
+	query, err := dnsDohPrepareA(domain)
+	if err != nil {
+		item.Err = err
+		return item, nil
+	}
+
+	conn, err := inetutil.GetUdpConn(inetutil.UdpConnOpt{Ip: ip, Port: cfg.Port})
+	if err != nil {
+		item.Err = err
+		return item, nil
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	resp, err := inetutil.UdpWriteReadPacket(ctx, conn, query)
+	if err != nil {
+		item.Err = err
+		return item, nil
+	}
+
+	h, records, err := dnsParseARecords(resp)
+	if err != nil {
+		log.Println("dnsinjectQuery", ip, domain, err)
+		item.Err = ErrDnsMalformed
+		return item, nil
+	}
+	item.Rcode = h.RCode
+	return item, records
+}
+
+func dnsinjectAnswers(records []dnsARecord) []DnsinjectAnswer {
+	out := []DnsinjectAnswer{}
+	for _, r := range records {
+		out = append(out, DnsinjectAnswer{IpInfo: inetlookup.Default().IpInfo(r.Ip), Ttl: r.Ttl})
+	}
+	return out
+}
+
+// Any datagram from a silent ip is injected, even if it is not a valid dns message.
+func dnsinjectVerdict(err error, control bool) error {
+	switch err {
+	case nil, ErrDnsMalformed:
+		if control {
+			return ErrDnsinjectNotSilent
+		}
+		return ErrDnsinjectInjected
+	case inetutil.ErrUdpReadTimeout, inetutil.ErrUdpPortUnreachable:
+		return nil
+	default:
+		return err
+	}
+}
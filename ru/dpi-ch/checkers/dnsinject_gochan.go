@@ -0,0 +1,85 @@
+package checkers
+
+import (
+	"context"
+	"log"
+	"net/netip"
+	"sync"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/gochan"
+)
+
+type DnsinjectGochanOut struct {
+	Bag WebhostGochanBag
+	Out DnsinjectSingleResult
+}
+
+type DnsinjectGochanRunnerOut struct {
+	Out      <-chan DnsinjectGochanOut
+	Progress <-chan string
+}
+
+// Checks the ips from config along with the farmed hosts; the former are grouped under the "Config" name.
+func DnsinjectGochanRunner(ctx context.Context) DnsinjectGochanRunnerOut {
+	cfg := config.Get().Checkers.Dnsinject
+	farmOut, progress := webhostFarmRunner(webhostFarmRunnerOpt[DnsinjectGochanOut]{
+		Ctx:     ctx,
+		Targets: cfg.Targets,
+		Name:    "dnsinject",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) DnsinjectGochanOut {
+			return DnsinjectGochanOut{
+				Bag: bag,
+				Out: DnsinjectSingle(DnsinjectSingleOpt{Ctx: in.Ctx, Ip: in.Ip}),
+			}
+		},
+	})
+
+	in := make(chan netip.Addr)
+	ipsOut := gochan.Start(gochan.GochanOpt[netip.Addr, DnsinjectGochanOut]{
+		Ctx:     ctx,
+		Workers: cfg.Workers,
+		Input:   in,
+		Executor: func(ip netip.Addr) DnsinjectGochanOut {
+			return DnsinjectGochanOut{
+				Bag: WebhostGochanBag{Name: "Config"},
+				Out: DnsinjectSingle(DnsinjectSingleOpt{Ctx: ctx, Ip: ip}),
+			}
+		},
+	})
+
+	ips := []netip.Addr{}
+	for _, s := range cfg.Ips {
+		ip, err := netip.ParseAddr(s)
+		if err != nil {
+			log.Println("dnsinject; invalid ip:", s)
+			continue
+		}
+		ips = append(ips, ip)
+	}
+	gochan.Push(ctx, in, ips)
+
+	out := make(chan DnsinjectGochanOut)
+	go func() {
+		defer close(out)
+		var wg sync.WaitGroup
+		for _, ch := range []<-chan DnsinjectGochanOut{ipsOut, farmOut} {
+			if ch == nil { // farm initialization error
+				continue
+			}
+			wg.Go(func() {
+				for v := range ch {
+					select {
+					case <-ctx.Done():
+						return
+					case out <- v:
+					}
+				}
+			})
+		}
+		wg.Wait()
+	}()
+
+	return DnsinjectGochanRunnerOut{Out: out, Progress: progress}
+}
@@ -0,0 +1,79 @@
+package checkers
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// Answers every datagram with resp (unless it is nil); returns its address.
+func dnsinjectTestServer(t *testing.T, resp []byte) netip.AddrPort {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		b := make([]byte, 1500)
+		for {
+			_, addr, err := conn.ReadFromUDP(b)
+			if err != nil {
+				return
+			}
+			if resp != nil {
+				conn.WriteToUDP(resp, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).AddrPort()
+}
+
+func TestDnsinjectQuery(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	config.Get().Checkers.Dnsinject.Timeout = 300 * time.Millisecond
+
+	closed, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.LocalAddr().(*net.UDPAddr).AddrPort()
+	closed.Close()
+
+	cases := map[string]struct {
+		addr    netip.AddrPort
+		control bool
+		want    error
+		ips     []netip.Addr
+	}{
+		"injected":    {dnsinjectTestServer(t, dnsTestResponse(t, dnsmessage.RCodeSuccess, "10.10.34.36")), false, ErrDnsinjectInjected, []netip.Addr{netip.MustParseAddr("10.10.34.36")}},
+		"nxdomain":    {dnsinjectTestServer(t, dnsTestResponse(t, dnsmessage.RCodeNameError)), false, ErrDnsinjectInjected, []netip.Addr{}},
+		"malformed":   {dnsinjectTestServer(t, []byte("<html>")), false, ErrDnsinjectInjected, nil},
+		"not silent":  {dnsinjectTestServer(t, dnsTestResponse(t, dnsmessage.RCodeSuccess, "1.1.1.1")), true, ErrDnsinjectNotSilent, []netip.Addr{netip.MustParseAddr("1.1.1.1")}},
+		"silent":      {dnsinjectTestServer(t, nil), false, nil, nil},
+		"unreachable": {closedAddr, false, nil, nil},
+	}
+	for name, tt := range cases {
+		config.Get().Checkers.Dnsinject.Port = int(tt.addr.Port())
+		item, records := dnsinjectQuery(context.Background(), tt.addr.Addr(), "example.com")
+
+		ips := []netip.Addr{}
+		for _, r := range records {
+			ips = append(ips, r.Ip)
+		}
+		if got := dnsinjectVerdict(item.Err, tt.control); got != tt.want || (tt.ips != nil && !slices.Equal(ips, tt.ips)) {
+			t.Fatalf("%s: got %v, %v; want %v, %v", name, got, ips, tt.want, tt.ips)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Checks if a censor drops or downgrades tls handshakes with Encrypted Client Hello (ech):
+// an ech handshake (with the real sni inside) is compared with a non-ech baseline for the same ip and sni.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/netip"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type EchSingleOpt struct {
+	Ctx           context.Context
+	Ip            netip.Addr
+	Port          int
+	Sni           string
+	EchConfigList []byte
+}
+
+type EchSingleResult struct {
+	IpInfo   inetlookup.IpInfo
+	Port     int
+	Sni      string
+	Baseline error // non-ech handshake
+	Ech      error // nil is accepted
+	Verdict  error
+}
+
+var (
+	ErrEchRetry       = errors.New("ech: accepted after retry (with configs offered by server)")
+	ErrEchNoConfig    = errors.New("ech: no ECHConfigList")
+	ErrEchBlocked     = errors.New("ech: blocked (non-ech is alive)")
+	ErrEchDowngraded  = errors.New("ech: rejected (downgraded to outer hello)")
+	ErrEchUnavailable = errors.New("ech: host unavailable")
+)
+
+func EchSingle(opt EchSingleOpt) EchSingleResult {
+	cfg := config.Get().Checkers.Ech
+	res := EchSingleResult{
+		IpInfo: inetlookup.Default().IpInfo(opt.Ip),
+		Port:   opt.Port,
+		Sni:    opt.Sni,
+	}
+
+	tlsConnOpt := inetutil.TlsConnOpt{
+		Ctx:                 opt.Ctx,
+		Ip:                  opt.Ip,
+		Port:                opt.Port,
+		Sni:                 opt.Sni,
+		TcpConnTimeout:      cfg.TcpConnTimeout,
+		TlsHandshakeTimeout: cfg.TlsHandshakeTimeout,
+	}
+	res.Baseline = echHandshake(tlsConnOpt)
+
+	switch {
+	case opt.EchConfigList == nil:
+		res.Ech = ErrEchNoConfig
+	case opt.Ctx.Err() != nil:
+		res.Ech = ErrWebhostSkip
+	default:
+		tlsConnOpt.EchConfigList = opt.EchConfigList
+		res.Ech = echHandshake(tlsConnOpt)
+		if rejection, ok := errors.AsType[*inetutil.EchRejectionError](res.Ech); ok && len(rejection.RetryConfigList) > 0 {
+			tlsConnOpt.EchConfigList = rejection.RetryConfigList
+			if err := echHandshake(tlsConnOpt); err == nil {
+				res.Ech = ErrEchRetry
+			}
+		}
+	}
+
+	res.Verdict = echVerdict(res.Baseline, res.Ech)
+	log.Println("ech; ip:", opt.Ip, "sni:", opt.Sni, "baseline:", res.Baseline, "ech:", res.Ech)
+	return res
+}
+
+func echHandshake(tlsConnOpt inetutil.TlsConnOpt) error {
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
+	if err != nil {
+		return err
+	}
+	defer tlsConn.Close()
+
+	if tlsConnOpt.EchConfigList != nil && !tlsConn.ConnectionState().ECHAccepted {
+		return inetutil.ErrTlsEchRejected
+	}
+	return nil
+}
+
+func echVerdict(baselineErr, echErr error) error {
+	switch {
+	case echErr == nil || echErr == ErrEchRetry:
+		return nil
+	case echErr == ErrEchNoConfig || echErr == ErrWebhostSkip:
+		return echErr
+	case baselineErr != nil:
+		return ErrEchUnavailable
+	case errors.Is(echErr, inetutil.ErrTlsEchRejected):
+		return ErrEchDowngraded
+	default:
+		return ErrEchBlocked
+	}
+}
+
+// Returns ECHConfigList from config, or from the HTTPS dns record of ech-config-host (via DoH).
+func EchConfigList(ctx context.Context) ([]byte, error) {
+	cfg := config.Get().Checkers.Ech
+	if cfg.EchConfig != "" {
+		return inetutil.ParseEchConfigList(cfg.EchConfig)
+	}
+
+	query, err := dnsDohPrepare(cfg.EchConfigHost, dnsmessage.TypeHTTPS)
+	if err != nil {
+		return nil, err
+	}
+
+	dohCfg := config.Get().Checkers.Dns.Resolve.DohOpt
+	for _, s := range cfg.Doh.Ips {
+		ip, err := netip.ParseAddr(s)
+		if err != nil {
+			log.Println("ech/doh; invalid ip:", s)
+			continue
+		}
+
+		innerCtx, cancel := context.WithTimeout(ctx, dohCfg.Timeout)
+		resp, err := dnsDohExchange(innerCtx, cfg.Doh.Host, ip, query)
+		cancel()
+		if err != nil {
+			log.Println("ech/doh;", cfg.Doh.Host, ip, err)
+			continue
+		}
+
+		if list := echFromHttpsRecord(resp); list != nil {
+			return list, nil
+		}
+		log.Println("ech/doh; no ech param in HTTPS record of", cfg.EchConfigHost)
+		return nil, ErrEchNoConfig
+	}
+
+	return nil, ErrEchNoConfig
+}
+
+// Returns the "ech" param of the first HTTPS record in the dns response.
+func echFromHttpsRecord(msg []byte) []byte {
+	var p dnsmessage.Parser
+	if _, err := p.Start(msg); err != nil {
+		return nil
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil
+	}
+
+	for {
+		h, err := p.AnswerHeader()
+		if err != nil {
+			return nil
+		}
+		if h.Type != dnsmessage.TypeHTTPS {
+			if err := p.SkipAnswer(); err != nil {
+				return nil
+			}
+			continue
+		}
+
+		r, err := p.HTTPSResource()
+		if err != nil {
+			return nil
+		}
+		if v, ok := r.GetParam(dnsmessage.SVCParamECH); ok {
+			return v
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package checkers
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+type EchGochanOut struct {
+	Bag WebhostGochanBag
+	Out EchSingleResult
+}
+
+type EchGochanRunnerOut struct {
+	Out      <-chan EchGochanOut
+	Progress <-chan string
+}
+
+func EchGochanRunner(ctx context.Context) EchGochanRunnerOut {
+	cfg := config.Get().Checkers.Ech
+	// ECHConfigList is fetched once (by the first worker)
+	echConfigList := sync.OnceValue(func() []byte {
+		list, err := EchConfigList(ctx)
+		if err != nil {
+			log.Println("ech; config list:", err)
+			return nil
+		}
+		return list
+	})
+
+	out, progress := webhostFarmRunner(webhostFarmRunnerOpt[EchGochanOut]{
+		Ctx:     ctx,
+		Targets: cfg.Targets,
+		Name:    "ech",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) EchGochanOut {
+			return EchGochanOut{
+				Bag: bag,
+				Out: EchSingle(EchSingleOpt{Ctx: in.Ctx, Ip: in.Ip, Port: in.Port, Sni: in.Sni, EchConfigList: echConfigList()}),
+			}
+		},
+	})
+	return EchGochanRunnerOut{Out: out, Progress: progress}
+}
@@ -0,0 +1,171 @@
+// Checks if a censor keys on the tls ClientHello fingerprint (ja3/ja4-style) rather than on sni or ip:
+// the same host is handshaked with every registered fingerprint, with and without the original alpn.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"net/http"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+
+	tls "github.com/refraction-networking/utls"
+)
+
+type FingerprintSingleOpt struct {
+	Ctx  context.Context
+	Ip   netip.Addr
+	Port int
+	Sni  string
+	Host string
+}
+
+type FingerprintItem struct {
+	Fingerprint  string
+	OriginalAlpn bool
+	Elapsed      time.Duration // tls handshake
+	Err          error
+	Verdict      error
+}
+
+type FingerprintSingleResult struct {
+	IpInfo inetlookup.IpInfo
+	Port   int
+	Sni    string
+	Items  []FingerprintItem
+}
+
+var (
+	ErrFingerprintDropped     = errors.New("fingerprint: dropped")
+	ErrFingerprintThrottled   = errors.New("fingerprint: throttled")
+	ErrFingerprintUnavailable = errors.New("fingerprint: host unavailable")
+)
+
+// Returns names of registered fingerprints in a stable order.
+func FingerprintNames() []string {
+	return slices.Sorted(maps.Keys(inetutil.Fingerprints))
+}
+
+func FingerprintSingle(opt FingerprintSingleOpt) FingerprintSingleResult {
+	res := FingerprintSingleResult{
+		IpInfo: inetlookup.Default().IpInfo(opt.Ip),
+		Port:   opt.Port,
+		Sni:    opt.Sni,
+	}
+
+	for _, name := range FingerprintNames() {
+		for _, originalAlpn := range []bool{false, true} {
+			item := FingerprintItem{Fingerprint: name, OriginalAlpn: originalAlpn}
+			if opt.Ctx.Err() != nil {
+				item.Err, item.Verdict = ErrWebhostSkip, ErrWebhostSkip
+			} else {
+				item = fingerprintCheck(opt, item)
+			}
+			res.Items = append(res.Items, item)
+		}
+	}
+
+	fingerprintRelativeVerdicts(res.Items)
+	return res
+}
+
+// The fingerprint is the cause only if some other one gets through to the same ip and sni;
+// otherwise the host (or the ip as a whole) is unavailable.
+func fingerprintRelativeVerdicts(items []FingerprintItem) {
+	if slices.ContainsFunc(items, func(x FingerprintItem) bool { return x.Verdict == nil }) {
+		return
+	}
+	for i := range items {
+		if items[i].Verdict == ErrFingerprintDropped || items[i].Verdict == ErrFingerprintThrottled {
+			items[i].Verdict = ErrFingerprintUnavailable
+		}
+	}
+}
+
+func fingerprintCheck(opt FingerprintSingleOpt, item FingerprintItem) FingerprintItem {
+	cfg := config.Get().Checkers.Fingerprint
+
+	start := time.Now()
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(inetutil.TlsConnOpt{
+		Ctx:                 opt.Ctx,
+		Ip:                  opt.Ip,
+		Port:                opt.Port,
+		Sni:                 opt.Sni,
+		TcpConnTimeout:      cfg.TcpConnTimeout,
+		TlsHandshakeTimeout: cfg.TlsHandshakeTimeout,
+		ClientHelloId:       *inetutil.Fingerprints[item.Fingerprint],
+		OriginalAlpn:        item.OriginalAlpn,
+	})
+	item.Elapsed = time.Since(start)
+	if err != nil {
+		item.Err = err
+		item.Verdict = fingerprintHandshakeVerdict(err)
+		log.Println("fingerprint; ip:", opt.Ip, "fp:", item.Fingerprint, "original alpn:", item.OriginalAlpn, "err:", err)
+		return item
+	}
+	defer tlsConn.Close()
+
+	if cfg.ThrottleThreshold > 0 && item.Elapsed > cfg.ThrottleThreshold {
+		item.Verdict = ErrFingerprintThrottled
+		return item
+	}
+
+	// the connection may also be frozen right after the handshake; it can only be checked over http/1.1
+	if tlsConn.ConnectionState().NegotiatedProtocol == "h2" {
+		return item
+	}
+
+	if err := fingerprintAliveCheck(opt, tlsConn); err != nil && err != inetutil.ErrHttpMalformedResponse {
+		item.Err = err
+		item.Verdict = ErrFingerprintThrottled
+		if err != inetutil.ErrTcpReadTimeout && err != inetutil.ErrTcpWriteTimeout {
+			item.Verdict = ErrFingerprintDropped
+		}
+	}
+
+	return item
+}
+
+// Only a handshake that was cut off or never answered may be keyed on the fingerprint;
+// connect-level and other tls errors (e.g. an ip block or incompatible ciphers) are not.
+func fingerprintHandshakeVerdict(err error) error {
+	switch err {
+	case inetutil.ErrTcpConnReset, inetutil.ErrTcpConnClosed, inetutil.ErrTlsHandshakeTimeout, inetutil.ErrTlsHandshakeFail:
+		return ErrFingerprintDropped
+	default:
+		return ErrFingerprintUnavailable
+	}
+}
+
+func fingerprintAliveCheck(opt FingerprintSingleOpt, tlsConn *tls.UConn) error {
+	cfg := config.Get().Checkers.Fingerprint
+
+	req, err := http.NewRequest("HEAD", "https://"+opt.Host, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Close = true
+	inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+
+	ctx, cancel := context.WithTimeout(opt.Ctx, cfg.ReadTimeout)
+	defer cancel()
+	if _, err := inetutil.TlsWriteHttpRequest(ctx, tlsConn, req); err != nil {
+		return err
+	}
+
+	resp, err := inetutil.TlsReadHttpResponse(ctx, tlsConn, bufio.NewReader(tlsConn))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,34 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+type FingerprintGochanOut struct {
+	Bag WebhostGochanBag
+	Out FingerprintSingleResult
+}
+
+type FingerprintGochanRunnerOut struct {
+	Out      <-chan FingerprintGochanOut
+	Progress <-chan string
+}
+
+func FingerprintGochanRunner(ctx context.Context) FingerprintGochanRunnerOut {
+	cfg := config.Get().Checkers.Fingerprint
+	out, progress := webhostFarmRunner(webhostFarmRunnerOpt[FingerprintGochanOut]{
+		Ctx:     ctx,
+		Targets: cfg.Targets,
+		Name:    "fingerprint",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) FingerprintGochanOut {
+			return FingerprintGochanOut{
+				Bag: bag,
+				Out: FingerprintSingle(FingerprintSingleOpt{Ctx: in.Ctx, Ip: in.Ip, Port: in.Port, Sni: in.Sni, Host: in.Host}),
+			}
+		},
+	})
+	return FingerprintGochanRunnerOut{Out: out, Progress: progress}
+}
@@ -0,0 +1,104 @@
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+func TestFingerprintCheck(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.Fingerprint
+	cfg.ReadTimeout = 300 * time.Millisecond
+
+	// the handshake always passes, so the host picks what happens after it
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "frozen.example.com":
+			<-r.Context().Done()
+		case "reset.example.com":
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.(*tls.Conn).NetConn().(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		}
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	// resets connections on the ClientHello
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Read(make([]byte, 4096))
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		}
+	}()
+	dropAddr := ln.Addr().(*net.TCPAddr).AddrPort()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedAddr := closed.Addr().(*net.TCPAddr).AddrPort()
+	closed.Close()
+
+	cases := []struct {
+		name      string
+		addr      netip.AddrPort
+		host      string
+		threshold time.Duration
+		want      error
+	}{
+		{"ok", addr, "example.com", 0, nil},
+		{"slow handshake", addr, "example.com", time.Nanosecond, ErrFingerprintThrottled},
+		{"frozen after handshake", addr, "frozen.example.com", 0, ErrFingerprintThrottled},
+		{"reset after handshake", addr, "reset.example.com", 0, ErrFingerprintDropped},
+		{"reset hello", dropAddr, "example.com", 0, ErrFingerprintDropped},
+		{"refused", refusedAddr, "example.com", 0, ErrFingerprintUnavailable},
+	}
+	for _, c := range cases {
+		cfg.ThrottleThreshold = c.threshold
+		opt := FingerprintSingleOpt{Ctx: context.Background(), Ip: c.addr.Addr(), Port: int(c.addr.Port()), Sni: "example.com", Host: c.host}
+		item := fingerprintCheck(opt, FingerprintItem{Fingerprint: FingerprintNames()[0]})
+		if item.Verdict != c.want {
+			t.Errorf("%s: got %v (%v), want %v", c.name, item.Verdict, item.Err, c.want)
+		}
+	}
+}
+
+func TestFingerprintRelativeVerdicts(t *testing.T) {
+	items := []FingerprintItem{{Verdict: ErrFingerprintDropped}, {Verdict: ErrFingerprintThrottled}, {Verdict: ErrWebhostSkip}}
+	fingerprintRelativeVerdicts(items)
+	for _, x := range items[:2] {
+		if x.Verdict != ErrFingerprintUnavailable {
+			t.Fatalf("none passed: got %v, want %v", x.Verdict, ErrFingerprintUnavailable)
+		}
+	}
+	if items[2].Verdict != ErrWebhostSkip {
+		t.Fatalf("none passed: got %v, want %v", items[2].Verdict, ErrWebhostSkip)
+	}
+
+	items = []FingerprintItem{{Verdict: ErrFingerprintDropped}, {Verdict: nil}}
+	fingerprintRelativeVerdicts(items)
+	if items[0].Verdict != ErrFingerprintDropped {
+		t.Fatalf("one passed: got %v, want %v", items[0].Verdict, ErrFingerprintDropped)
+	}
+}
@@ -1,6 +1,7 @@
 package checkers
 
 import (
+	"cmp"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -18,6 +19,7 @@ import (
 	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
 	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
 	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+	"github.com/quic-go/quic-go"
 	yaml "go.yaml.in/yaml/v3"
 )
 
@@ -35,6 +37,14 @@ type FullCheckWhoamiDto struct {
 	Org      string
 	Location string
 	TtlbMs   int64
+	Nat      FullCheckWhoamiNatDto
+}
+
+type FullCheckWhoamiNatDto struct {
+	Status FullCheckStatusDto
+	Hint   string
+	Local  string
+	Mapped []string
 }
 
 type FullCheckCidrwhitelistDto struct {
@@ -52,9 +62,13 @@ type FullCheckDnsReportDto struct {
 }
 
 type FullCheckDnsDto struct {
-	Leak  *FullCheckDnsLeakDto
-	Plain *FullCheckDnsReportDto
-	Doh   *FullCheckDnsReportDto
+	Leak     *FullCheckDnsLeakDto
+	Plain    *FullCheckDnsReportDto // over udp only (the report key is kept); tcp is in PlainTcp
+	PlainTcp *FullCheckDnsReportDto
+	Doh      *FullCheckDnsReportDto
+	Dot      *FullCheckDnsReportDto
+	Doq      *FullCheckDnsReportDto
+	Doh3     *FullCheckDnsReportDto
 }
 
 type FullCheckWebhostTls struct {
@@ -63,6 +77,12 @@ type FullCheckWebhostTls struct {
 	Cn  string
 }
 
+type FullCheckWebhostSniMatrixItemDto struct {
+	Kind   string
+	Sni    string
+	Status FullCheckStatusDto
+}
+
 type FullCheckWebhostItemDto struct {
 	Group       string
 	Org         string
@@ -71,11 +91,36 @@ type FullCheckWebhostItemDto struct {
 	IP          string
 	Prefix      string
 	Alive       FullCheckStatusDto
+	AliveHttp   *FullCheckWebhostAliveHttpDto // set only if the http response to the alive check is received
 	Tls         *FullCheckWebhostTls
 	Tcp1620     FullCheckStatusDto
-	Siberian    FullCheckStatusDto
-	BurstTxKbps *float64
-	BurstRxKbps *float64
+	Tcp1620Down FullCheckStatusDto
+	// Largest request (in bytes) that got through before the tcp 16-20 freeze
+	Tcp1620CutoffBytes *int64
+	L4_25              FullCheckStatusDto
+	Siberian           FullCheckStatusDto
+	PqKeyShare         FullCheckStatusDto
+	BurstTxKbps        *float64
+	BurstRxKbps        *float64
+	DownRxKbps         *float64
+	SniMatrix          []FullCheckWebhostSniMatrixItemDto
+	// Set only if throughput-series is enabled
+	ThroughputSeries *FullCheckWebhostSeriesDto
+}
+
+type FullCheckWebhostAliveHttpDto struct {
+	Status   int
+	Location string
+}
+
+type FullCheckWebhostSeriesDto struct {
+	StepMs   int64
+	RxBytes  []int64 // cumulative, at the end of each step
+	Complete bool
+	Verdict  FullCheckStatusDto
+	// Set only if the host is frozen or throttled
+	PlateauStartMs *int64
+	PlateauKbps    *float64
 }
 
 type FullCheckWebhostDto struct {
@@ -87,11 +132,281 @@ type FullCheckStatusDto struct {
 	Code string
 }
 
+type FullCheckCompressionItemDto struct {
+	Url          string
+	Encoding     string
+	HttpStatus   int
+	Compressed   int64
+	Decompressed int64
+	Verdict      FullCheckStatusDto
+}
+
+type FullCheckCompressionDto struct {
+	Items []FullCheckCompressionItemDto
+}
+
+type FullCheckSniWhitelistDto struct {
+	Status      FullCheckStatusDto
+	DstIp       string
+	Total       int
+	Errors      int
+	Whitelisted []string
+}
+
+type FullCheckQuicItemDto struct {
+	Group       string
+	Org         string
+	AS          string
+	Location    string
+	IP          string
+	Prefix      string
+	Sni         string
+	Alive       FullCheckStatusDto
+	Quic        FullCheckStatusDto
+	QuicVersion string
+	Verdict     FullCheckStatusDto
+}
+
+type FullCheckQuicDto struct {
+	Items []FullCheckQuicItemDto
+}
+
+type FullCheckFingerprintItemDto struct {
+	Fingerprint  string
+	OriginalAlpn bool
+	ElapsedMs    int64
+	Verdict      FullCheckStatusDto
+}
+
+type FullCheckFingerprintHostDto struct {
+	Group    string
+	Org      string
+	AS       string
+	Location string
+	IP       string
+	Prefix   string
+	Sni      string
+	Items    []FullCheckFingerprintItemDto
+}
+
+type FullCheckFingerprintDto struct {
+	Items []FullCheckFingerprintHostDto
+}
+
+type FullCheckBypassStrategyDto struct {
+	Strategy string
+	Status   FullCheckStatusDto
+}
+
+type FullCheckBypassItemDto struct {
+	Group      string
+	Org        string
+	AS         string
+	Location   string
+	IP         string
+	Prefix     string
+	Sni        string
+	Baseline   FullCheckStatusDto
+	Strategies []FullCheckBypassStrategyDto
+}
+
+type FullCheckBypassDto struct {
+	Items []FullCheckBypassItemDto
+}
+
+type FullCheckEchItemDto struct {
+	Group    string
+	Org      string
+	AS       string
+	Location string
+	IP       string
+	Prefix   string
+	Sni      string
+	Baseline FullCheckStatusDto
+	Ech      FullCheckStatusDto
+	Verdict  FullCheckStatusDto
+}
+
+type FullCheckEchDto struct {
+	Items []FullCheckEchItemDto
+}
+
+type FullCheckHttpHostProbeDto struct {
+	Host       string
+	HttpStatus int
+	Location   string
+	Verdict    FullCheckStatusDto
+}
+
+type FullCheckHttpHostItemDto struct {
+	Target  string
+	IP      string
+	Status  FullCheckStatusDto
+	Real    *FullCheckHttpHostProbeDto
+	Random  *FullCheckHttpHostProbeDto
+	Mangled *FullCheckHttpHostProbeDto
+}
+
+type FullCheckHttpHostDto struct {
+	Items []FullCheckHttpHostItemDto
+}
+
+type FullCheckTunnelProbeDto struct {
+	Protocol string
+	Result   FullCheckStatusDto
+	Verdict  FullCheckStatusDto
+}
+
+type FullCheckTunnelItemDto struct {
+	Name    string
+	Host    string
+	Port    int
+	IP      string
+	Status  FullCheckStatusDto
+	Control *FullCheckStatusDto
+	Probes  []FullCheckTunnelProbeDto
+}
+
+type FullCheckTunnelDto struct {
+	Items []FullCheckTunnelItemDto
+}
+
+type FullCheckUdpvpnProbeDto struct {
+	Protocol string
+	Result   FullCheckStatusDto
+	Verdict  FullCheckStatusDto
+}
+
+type FullCheckUdpvpnItemDto struct {
+	Name    string
+	Host    string
+	Port    int
+	IP      string
+	Status  FullCheckStatusDto
+	Control *FullCheckStatusDto
+	Probes  []FullCheckUdpvpnProbeDto
+}
+
+type FullCheckUdpvpnDto struct {
+	Items []FullCheckUdpvpnItemDto
+}
+
+type FullCheckStunTransportDto struct {
+	Status   FullCheckStatusDto
+	Mapped   string
+	RttMs    int64
+	Sent     int
+	Received int
+	LossPct  int
+}
+
+type FullCheckStunItemDto struct {
+	Name    string
+	Host    string
+	Port    int
+	IP      string
+	Status  FullCheckStatusDto
+	Udp     *FullCheckStunTransportDto
+	Tcp     *FullCheckStunTransportDto
+	Verdict *FullCheckStatusDto
+}
+
+type FullCheckStunDto struct {
+	Items []FullCheckStunItemDto
+}
+
+type FullCheckIdleItemDto struct {
+	Keepalive  string
+	IntervalMs int64
+	Verdict    FullCheckStatusDto
+}
+
+type FullCheckIdleHostDto struct {
+	Group     string
+	Org       string
+	AS        string
+	Location  string
+	IP        string
+	Prefix    string
+	Sni       string
+	LongestMs map[string]int64 // by keepalive; the longest interval that survived (0 is none)
+	Items     []FullCheckIdleItemDto
+}
+
+type FullCheckIdleDto struct {
+	Items []FullCheckIdleHostDto
+}
+
+type FullCheckPortscanItemDto struct {
+	Port    int
+	Verdict FullCheckStatusDto
+}
+
+type FullCheckPortscanHostDto struct {
+	Group    string
+	Org      string
+	AS       string
+	Location string
+	IP       string
+	Prefix   string
+	Sni      string
+	Baseline FullCheckPortscanItemDto
+	Items    []FullCheckPortscanItemDto
+}
+
+type FullCheckPortscanDto struct {
+	Items []FullCheckPortscanHostDto
+}
+
+type FullCheckDnsinjectAnswerDto struct {
+	IP       string
+	Org      string
+	AS       string
+	Location string
+	Ttl      uint32
+}
+
+type FullCheckDnsinjectItemDto struct {
+	Domain  string
+	Rcode   string
+	Answers []FullCheckDnsinjectAnswerDto
+	Verdict FullCheckStatusDto
+}
+
+type FullCheckDnsinjectHostDto struct {
+	Group    string
+	Org      string
+	AS       string
+	Location string
+	IP       string
+	Prefix   string
+	Verdict  FullCheckStatusDto
+	Controls []FullCheckDnsinjectItemDto
+	Items    []FullCheckDnsinjectItemDto
+}
+
+type FullCheckDnsinjectDto struct {
+	Items []FullCheckDnsinjectHostDto
+}
+
 type FullCheckDto struct {
 	Whoami        *FullCheckWhoamiDto
 	CidrWhitelist *FullCheckCidrwhitelistDto
 	Dns           *FullCheckDnsDto
 	Webhost       map[string]FullCheckWebhostDto
+	Compression   *FullCheckCompressionDto
+	SniWhitelist  *FullCheckSniWhitelistDto
+	Quic          *FullCheckQuicDto
+	HttpHost      *FullCheckHttpHostDto
+	Fingerprint   *FullCheckFingerprintDto
+	Bypass        *FullCheckBypassDto
+	Ech           *FullCheckEchDto
+	Tunnel        *FullCheckTunnelDto
+	Udpvpn        map[string]FullCheckUdpvpnDto
+	Stun          *FullCheckStunDto
+	Idle          *FullCheckIdleDto
+	Portscan      *FullCheckPortscanDto
+	Dnsinject     *FullCheckDnsinjectDto
 }
 
 func FullCheckGochan(ctx context.Context) <-chan FullCheckProgress {
@@ -125,7 +440,11 @@ func FullCheckGochan(ctx context.Context) <-chan FullCheckProgress {
 
 		var dnsLeak *FullCheckDnsLeakDto
 		var dnsPlain *FullCheckDnsReportDto
+		var dnsPlainTcp *FullCheckDnsReportDto
 		var dnsDoh *FullCheckDnsReportDto
+		var dnsDot *FullCheckDnsReportDto
+		var dnsDoq *FullCheckDnsReportDto
+		var dnsDoh3 *FullCheckDnsReportDto
 		if slices.Contains(cfg.All.Checkers, "dns") {
 			wg.Go(func() {
 				gch := DnsLeakGochan(ctx)
@@ -139,13 +458,14 @@ func FullCheckGochan(ctx context.Context) <-chan FullCheckProgress {
 			})
 
 			wg.Go(func() {
-				items := []DnsVerdict{}
+				udpItems, tcpItems := []DnsVerdict{}, []DnsVerdict{}
 				gch := DnsPlainGochan(ctx)
 				for v := range gch {
-					items = append(items, v)
+					udpItems = append(udpItems, DnsVerdict{Provider: v.Provider, Verdict: v.Udp})
+					tcpItems = append(tcpItems, DnsVerdict{Provider: v.Provider, Verdict: v.Tcp})
 				}
-				val := fullCheckDnsReportDto(items)
-				dnsPlain = &val
+				udpVal, tcpVal := fullCheckDnsReportDto(udpItems), fullCheckDnsReportDto(tcpItems)
+				dnsPlain, dnsPlainTcp = &udpVal, &tcpVal
 				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "dns:plain ready"})
 			})
 
@@ -159,6 +479,39 @@ func FullCheckGochan(ctx context.Context) <-chan FullCheckProgress {
 				dnsDoh = &val
 				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "dns:doh ready"})
 			})
+
+			wg.Go(func() {
+				items := []DnsVerdict{}
+				gch := DnsDotGochan(ctx)
+				for v := range gch {
+					items = append(items, v)
+				}
+				val := fullCheckDnsReportDto(items)
+				dnsDot = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "dns:dot ready"})
+			})
+
+			wg.Go(func() {
+				items := []DnsVerdict{}
+				gch := DnsDoqGochan(ctx)
+				for v := range gch {
+					items = append(items, v)
+				}
+				val := fullCheckDnsReportDto(items)
+				dnsDoq = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "dns:doq ready"})
+			})
+
+			wg.Go(func() {
+				items := []DnsVerdict{}
+				gch := DnsDoh3Gochan(ctx)
+				for v := range gch {
+					items = append(items, v)
+				}
+				val := fullCheckDnsReportDto(items)
+				dnsDoh3 = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "dns:doh3 ready"})
+			})
 		}
 
 		var webhostMu sync.Mutex
@@ -183,15 +536,202 @@ func FullCheckGochan(ctx context.Context) <-chan FullCheckProgress {
 			}
 		}
 
+		var compression *FullCheckCompressionDto
+		if slices.Contains(cfg.All.Checkers, "compression") {
+			wg.Go(func() {
+				items := []CompressionResult{}
+				for v := range CompressionGochan(ctx) {
+					items = append(items, v)
+				}
+				val := fullCheckCompressionDto(items)
+				compression = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "compression ready"})
+			})
+		}
+
+		var sniwhitelist *FullCheckSniWhitelistDto
+		if slices.Contains(cfg.All.Checkers, "sniwhitelist") {
+			wg.Go(func() {
+				items, err := SniWhitelist(ctx)
+				val := fullCheckSniWhitelistDto(items, err)
+				sniwhitelist = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "sniwhitelist ready"})
+			})
+		}
+
+		var quicDto *FullCheckQuicDto
+		if slices.Contains(cfg.All.Checkers, "quic") {
+			wg.Go(func() {
+				items := []FullCheckQuicItemDto{}
+				for o := range QuicGochanRunner(ctx).Out {
+					items = append(items, fullCheckQuicItemDto(o))
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf(`quic: "%s" ready`, o.Bag.Name)})
+				}
+				quicDto = &FullCheckQuicDto{Items: items}
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "quic ready"})
+			})
+		}
+
+		var fingerprint *FullCheckFingerprintDto
+		if slices.Contains(cfg.All.Checkers, "fingerprint") {
+			wg.Go(func() {
+				items := []FullCheckFingerprintHostDto{}
+				for o := range FingerprintGochanRunner(ctx).Out {
+					items = append(items, fullCheckFingerprintHostDto(o))
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf(`fingerprint: "%s" ready`, o.Bag.Name)})
+				}
+				fingerprint = &FullCheckFingerprintDto{Items: items}
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "fingerprint ready"})
+			})
+		}
+
+		var bypass *FullCheckBypassDto
+		if slices.Contains(cfg.All.Checkers, "bypass") {
+			wg.Go(func() {
+				items := []FullCheckBypassItemDto{}
+				for o := range BypassGochanRunner(ctx).Out {
+					items = append(items, fullCheckBypassItemDto(o))
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf(`bypass: "%s" ready`, o.Bag.Name)})
+				}
+				bypass = &FullCheckBypassDto{Items: items}
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "bypass ready"})
+			})
+		}
+
+		var ech *FullCheckEchDto
+		if slices.Contains(cfg.All.Checkers, "ech") {
+			wg.Go(func() {
+				items := []FullCheckEchItemDto{}
+				for o := range EchGochanRunner(ctx).Out {
+					items = append(items, fullCheckEchItemDto(o))
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf(`ech: "%s" ready`, o.Bag.Name)})
+				}
+				ech = &FullCheckEchDto{Items: items}
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "ech ready"})
+			})
+		}
+
+		var idle *FullCheckIdleDto
+		if slices.Contains(cfg.All.Checkers, "idle") {
+			wg.Go(func() {
+				items := []FullCheckIdleHostDto{}
+				for o := range IdleGochanRunner(ctx).Out {
+					items = append(items, fullCheckIdleHostDto(o))
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf(`idle: "%s" ready`, o.Bag.Name)})
+				}
+				idle = &FullCheckIdleDto{Items: items}
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "idle ready"})
+			})
+		}
+
+		var portscan *FullCheckPortscanDto
+		if slices.Contains(cfg.All.Checkers, "portscan") {
+			wg.Go(func() {
+				items := []FullCheckPortscanHostDto{}
+				for o := range PortscanGochanRunner(ctx).Out {
+					items = append(items, fullCheckPortscanHostDto(o))
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf(`portscan: "%s" ready`, o.Bag.Name)})
+				}
+				portscan = &FullCheckPortscanDto{Items: items}
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "portscan ready"})
+			})
+		}
+
+		var dnsinject *FullCheckDnsinjectDto
+		if slices.Contains(cfg.All.Checkers, "dnsinject") {
+			wg.Go(func() {
+				items := []FullCheckDnsinjectHostDto{}
+				for o := range DnsinjectGochanRunner(ctx).Out {
+					items = append(items, fullCheckDnsinjectHostDto(o))
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf(`dnsinject: "%s" ready`, o.Bag.Name)})
+				}
+				dnsinject = &FullCheckDnsinjectDto{Items: items}
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "dnsinject ready"})
+			})
+		}
+
+		var httphost *FullCheckHttpHostDto
+		if slices.Contains(cfg.All.Checkers, "httphost") {
+			wg.Go(func() {
+				items := []HttpHostResult{}
+				for v := range HttpHostGochan(ctx) {
+					items = append(items, v)
+				}
+				val := fullCheckHttpHostDto(items)
+				httphost = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "httphost ready"})
+			})
+		}
+
+		var tunnel *FullCheckTunnelDto
+		if slices.Contains(cfg.All.Checkers, "tunnel") {
+			wg.Go(func() {
+				items := []TunnelResult{}
+				for v := range TunnelGochan(ctx) {
+					items = append(items, v)
+				}
+				val := fullCheckTunnelDto(items)
+				tunnel = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "tunnel ready"})
+			})
+		}
+
+		var udpvpnMu sync.Mutex
+		udpvpn := map[string]FullCheckUdpvpnDto{}
+		if slices.Contains(cfg.All.Checkers, "udpvpn") {
+			for _, s := range cfg.Checkers.Udpvpn.Sections {
+				wg.Go(func() {
+					items := []UdpvpnResult{}
+					for v := range UdpvpnGochan(ctx, s.Targets) {
+						items = append(items, v)
+					}
+					udpvpnMu.Lock()
+					udpvpn[s.Name] = fullCheckUdpvpnDto(items)
+					udpvpnMu.Unlock()
+					fullCheckSendProgress(progressCh, FullCheckProgress{Msg: fmt.Sprintf("udpvpn[%s] ready", s.Name)})
+				})
+			}
+		}
+
+		var stun *FullCheckStunDto
+		if slices.Contains(cfg.All.Checkers, "stun") {
+			wg.Go(func() {
+				items := []StunResult{}
+				for v := range StunGochan(ctx) {
+					items = append(items, v)
+				}
+				val := fullCheckStunDto(items)
+				stun = &val
+				fullCheckSendProgress(progressCh, FullCheckProgress{Msg: "stun ready"})
+			})
+		}
+
 		wg.Wait()
 		r.Whoami = whoami
+		r.Stun = stun
+		r.Udpvpn = udpvpn
+		r.Tunnel = tunnel
+		r.HttpHost = httphost
+		r.Fingerprint = fingerprint
+		r.Bypass = bypass
+		r.Ech = ech
+		r.Idle = idle
+		r.Portscan = portscan
+		r.Dnsinject = dnsinject
+		r.Quic = quicDto
+		r.SniWhitelist = sniwhitelist
+		r.Compression = compression
 		r.CidrWhitelist = cidrwhitelist
 		r.Webhost = webhost
-		if dnsLeak != nil || dnsPlain != nil || dnsDoh != nil {
+		if dnsLeak != nil || dnsPlain != nil || dnsPlainTcp != nil || dnsDoh != nil || dnsDot != nil || dnsDoq != nil || dnsDoh3 != nil {
 			r.Dns = &FullCheckDnsDto{
-				Leak:  dnsLeak,
-				Plain: dnsPlain,
-				Doh:   dnsDoh,
+				Leak:     dnsLeak,
+				Plain:    dnsPlain,
+				PlainTcp: dnsPlainTcp,
+				Doh:      dnsDoh,
+				Dot:      dnsDot,
+				Doq:      dnsDoq,
+				Doh3:     dnsDoh3,
 			}
 		}
 
@@ -264,9 +804,25 @@ func fullCheckWhoamiDto(r WhoamiResult, err error) FullCheckWhoamiDto {
 		Location: r.Location,
 		TtlbMs:   r.Ttlb.Milliseconds(),
 		Status:   FullCheckStatusDto{Msg: "Ok", Code: "OK"},
+		Nat:      fullCheckWhoamiNatDto(r.Nat),
 	}
 	if err != nil {
 		x.Status = FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+		x.Nat.Status = x.Status
+	}
+	return x
+}
+
+func fullCheckWhoamiNatDto(r StunNatResult) FullCheckWhoamiNatDto {
+	x := FullCheckWhoamiNatDto{Status: FullCheckStatusDto{Msg: "Ok", Code: "OK"}, Hint: r.Hint, Mapped: []string{}}
+	if r.Local.IsValid() {
+		x.Local = r.Local.String()
+	}
+	for _, m := range r.Mapped {
+		x.Mapped = append(x.Mapped, m.String())
+	}
+	if r.Err != nil {
+		x.Status = FullCheckStatusDto{Msg: r.Err.Error(), Code: "ERR"}
 	}
 	return x
 }
@@ -317,14 +873,28 @@ func fullCheckPrettyDnsVerdict(v error) (string, string) {
 		return "NXDOMAIN spoofing", "NXDOMAIN_SPOOFING"
 	case ErrDnsResolveSpoofing:
 		return "Response spoofing", "RESPONSE_SPOOFING"
+	case ErrDnsUdpInjection:
+		return "UDP injection", "UDP_INJECTION"
 	case ErrDnsDohBootstrapSpoofing:
 		return "Bootstrap spoofing", "BOOTSTRAP_SPOOFING"
 	case ErrDnsDohBootstrapEmpty:
 		return "Empty bootstrap", "EMPTY_BOOTSTRAP"
-	case ErrDnsDohInsecure, inetutil.ErrTlsCertificateInvalid:
+	case ErrDnsDohInsecure, ErrDnsDoh3Insecure, inetutil.ErrTlsCertificateInvalid:
 		return "Invalid https certificate", "INVALID_HTTPS_CERT"
+	case ErrDnsDotInsecure, ErrDnsDoqInsecure:
+		return "Invalid tls certificate", "INVALID_TLS_CERT"
 	case ErrDnsDohNon2xxResp:
 		return "Non-2xx response", "NON_2XX_RESP"
+	case ErrDnsServfail:
+		return "SERVFAIL", "SERVFAIL"
+	case ErrDnsRefused:
+		return "REFUSED", "REFUSED"
+	case ErrDnsRcode:
+		return "Unexpected RCODE", "UNEXPECTED_RCODE"
+	case ErrDnsEmptyAnswer:
+		return "Empty answer", "EMPTY_ANSWER"
+	case ErrDnsMalformed:
+		return "Malformed response", "MALFORMED_RESP"
 	case ErrDnsSkip:
 		return "Skip", "SKIP"
 	default:
@@ -341,17 +911,91 @@ func fullCheckDnsReportDto(verdicts []DnsVerdict) FullCheckDnsReportDto {
 	return FullCheckDnsReportDto{Status: FullCheckStatusDto{Msg: "Ok", Code: "OK"}, Providers: providers}
 }
 
+func fullCheckSniWhitelistDto(items []SniWhitelistResult, err error) FullCheckSniWhitelistDto {
+	x := FullCheckSniWhitelistDto{
+		Status:      FullCheckStatusDto{Msg: "Ok", Code: "OK"},
+		DstIp:       config.Get().Checkers.SniWhitelist.DstIp,
+		Total:       len(items),
+		Whitelisted: []string{},
+	}
+
+	switch err {
+	case nil:
+	case ErrSniWhitelistNoDstIp:
+		x.Status = FullCheckStatusDto{Msg: "Invalid or empty destination ip", Code: "NO_DST_IP"}
+	case ErrSniWhitelistNoInput:
+		x.Status = FullCheckStatusDto{Msg: "Empty input list", Code: "NO_INPUT"}
+	default:
+		x.Status = FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+
+	for _, v := range items {
+		if v.Whitelisted {
+			x.Whitelisted = append(x.Whitelisted, v.Sni)
+		}
+		if v.Err != nil {
+			x.Errors++
+		}
+	}
+	slices.Sort(x.Whitelisted)
+
+	return x
+}
+
+func fullCheckCompressionDto(results []CompressionResult) FullCheckCompressionDto {
+	items := []FullCheckCompressionItemDto{}
+	for _, x := range results {
+		items = append(items, FullCheckCompressionItemDto{
+			Url:          x.Url,
+			Encoding:     x.Encoding,
+			HttpStatus:   x.HttpStatus,
+			Compressed:   x.Compressed,
+			Decompressed: x.Decompressed,
+			Verdict:      compressionPrettyVerdict(x.Verdict),
+		})
+	}
+
+	slices.SortFunc(items, func(a, b FullCheckCompressionItemDto) int {
+		return cmp.Or(strings.Compare(a.Url, b.Url), strings.Compare(a.Encoding, b.Encoding))
+	})
+	return FullCheckCompressionDto{Items: items}
+}
+
+func compressionPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrCompressionNotSupported:
+		return FullCheckStatusDto{Msg: "Not supported by host", Code: "NOT_SUPPORTED_BY_HOST"}
+	case ErrCompressionEofBeforeMin:
+		return FullCheckStatusDto{Msg: "EOF before min", Code: "EOF_BEFORE_MIN"}
+	case ErrCompressionTimeout:
+		return FullCheckStatusDto{Msg: "Timeout", Code: "TIMEOUT"}
+	case ErrCompressionConnErr:
+		return FullCheckStatusDto{Msg: "Connection error", Code: "CONN_ERR"}
+	default:
+		return FullCheckStatusDto{Msg: "Internal error", Code: "INTERNAL_ERR"}
+	}
+}
+
 func fullCheckWebhostItemDto(o WebhostGochanOut[WebhostGochanBag]) FullCheckWebhostItemDto {
 	dto := FullCheckWebhostItemDto{
-		Group:    o.Bag.Name,
-		Org:      o.Out.IpInfo.Org,
-		AS:       fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
-		Location: o.Out.IpInfo.CountryIso,
-		IP:       o.Out.IpInfo.Ip.String(),
-		Prefix:   o.Out.IpInfo.Subnet.String(),
-		Alive:    webhostPrettyAlive(o.Out.Alive),
-		Tcp1620:  webhostPrettyTcp1620(o.Out.Tcp1620),
-		Siberian: webhostPrettySiberian(o.Out.Siberian),
+		Group:       o.Bag.Name,
+		Org:         o.Out.IpInfo.Org,
+		AS:          fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location:    o.Out.IpInfo.CountryIso,
+		IP:          o.Out.IpInfo.Ip.String(),
+		Prefix:      o.Out.IpInfo.Subnet.String(),
+		Alive:       webhostPrettyAlive(o.Out.Alive),
+		Tcp1620:     webhostPrettyTcp1620(o.Out.Tcp1620),
+		Tcp1620Down: webhostPrettyTcp1620(o.Out.Tcp1620Down),
+		L4_25:       webhostPrettyL4_25(o.Out.L4_25),
+		Siberian:    webhostPrettySiberian(o.Out.Siberian),
+		PqKeyShare:  webhostPrettyPqKeyShare(o.Out.PqKeyShare),
+	}
+
+	if o.Out.AliveResponse != nil {
+		dto.AliveHttp = &FullCheckWebhostAliveHttpDto{Status: o.Out.AliveResponse.Status, Location: o.Out.AliveResponse.Location}
 	}
 
 	if o.Out.Tls != nil {
@@ -367,26 +1011,645 @@ func fullCheckWebhostItemDto(o WebhostGochanOut[WebhostGochanBag]) FullCheckWebh
 		dto.BurstRxKbps = &rxKbps
 	}
 
-	return dto
-}
+	for _, x := range o.Out.SniMatrix {
+		dto.SniMatrix = append(dto.SniMatrix, FullCheckWebhostSniMatrixItemDto{
+			Kind:   x.Kind,
+			Sni:    x.Sni,
+			Status: webhostPrettySniMatrixItem(x.Err),
+		})
+	}
 
-func webhostPrettyAlive(err error) FullCheckStatusDto {
-	switch err {
-	case nil:
-		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
-	case inetutil.ErrHttpMalformedResponse:
-		return FullCheckStatusDto{Msg: "Ok, custom HTTP", Code: "OK_CUSTOM_HTTP"}
+	if o.Out.Tcp1620Cutoff > 0 {
+		cutoff := o.Out.Tcp1620Cutoff
+		dto.Tcp1620CutoffBytes = &cutoff
 	}
-	return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
-}
 
-func webhostPrettyTlsV(v uint16) string {
-	switch v {
-	case tls.VersionTLS10:
-		return "1.0"
-	case tls.VersionTLS11:
-		return "1.1"
-	case tls.VersionTLS12:
+	if o.Out.ThroughputDown.RxElapsed > 0 {
+		const bytesToKilobits = 8.0 / 1_000
+		rxKbps := float64(o.Out.ThroughputDown.RxBytes) / o.Out.ThroughputDown.RxElapsed.Seconds() * bytesToKilobits
+		dto.DownRxKbps = &rxKbps
+	}
+
+	if x := o.Out.ThroughputSeries; x != nil {
+		dto.ThroughputSeries = &FullCheckWebhostSeriesDto{
+			StepMs:   x.Step.Milliseconds(),
+			RxBytes:  x.Samples,
+			Complete: x.Complete,
+			Verdict:  webhostPrettySeries(x.Verdict),
+		}
+		if x.Verdict == ErrWebhostSeriesFrozen || x.Verdict == ErrWebhostSeriesThrottled {
+			start, kbps := x.PlateauStart.Milliseconds(), x.PlateauKbps
+			dto.ThroughputSeries.PlateauStartMs = &start
+			dto.ThroughputSeries.PlateauKbps = &kbps
+		}
+	}
+
+	return dto
+}
+
+func fullCheckHttpHostDto(results []HttpHostResult) FullCheckHttpHostDto {
+	items := []FullCheckHttpHostItemDto{}
+	for _, x := range results {
+		item := FullCheckHttpHostItemDto{Target: x.Target, Status: FullCheckStatusDto{Msg: "Ok", Code: "OK"}}
+		if x.Err != nil {
+			item.Status = FullCheckStatusDto{Msg: "Lookup error", Code: "LOOKUP_ERR"}
+			items = append(items, item)
+			continue
+		}
+
+		probe := func(p HttpHostProbe) *FullCheckHttpHostProbeDto {
+			return &FullCheckHttpHostProbeDto{
+				Host:       p.Host,
+				HttpStatus: p.HttpStatus,
+				Location:   p.Location,
+				Verdict:    httphostPrettyVerdict(p.Verdict),
+			}
+		}
+		item.IP = x.Ip.String()
+		item.Real = probe(x.Real)
+		item.Random = probe(x.Random)
+		item.Mangled = probe(x.Mangled)
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(a, b FullCheckHttpHostItemDto) int {
+		return strings.Compare(a.Target, b.Target)
+	})
+	return FullCheckHttpHostDto{Items: items}
+}
+
+func fullCheckTunnelDto(results []TunnelResult) FullCheckTunnelDto {
+	items := []FullCheckTunnelItemDto{}
+	for _, x := range results {
+		item := FullCheckTunnelItemDto{Name: x.Name, Host: x.Host, Port: x.Port, Status: FullCheckStatusDto{Msg: "Ok", Code: "OK"}}
+		if x.Err != nil {
+			item.Status = FullCheckStatusDto{Msg: "Lookup error", Code: "LOOKUP_ERR"}
+			items = append(items, item)
+			continue
+		}
+
+		control := tunnelPrettyResult(x.Control)
+		item.IP = x.Ip.String()
+		item.Control = &control
+		for _, p := range x.Probes {
+			item.Probes = append(item.Probes, FullCheckTunnelProbeDto{
+				Protocol: p.Protocol,
+				Result:   tunnelPrettyResult(p.Err),
+				Verdict:  tunnelPrettyVerdict(p.Verdict),
+			})
+		}
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(a, b FullCheckTunnelItemDto) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return FullCheckTunnelDto{Items: items}
+}
+
+func tunnelPrettyResult(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Answered", Code: "ANSWERED"}
+	case inetutil.ErrTcpConnReset:
+		return FullCheckStatusDto{Msg: "Connection reset", Code: "RESET"}
+	case inetutil.ErrTcpReadTimeout:
+		return FullCheckStatusDto{Msg: "Silence", Code: "SILENCE"}
+	case inetutil.ErrTcpConnClosed:
+		return FullCheckStatusDto{Msg: "Connection closed", Code: "CLOSED"}
+	case inetutil.ErrTcpConnTimeout:
+		return FullCheckStatusDto{Msg: "Connection timeout", Code: "CONN_TIMEOUT"}
+	case ErrTunnelUnknownProtocol:
+		return FullCheckStatusDto{Msg: "Unknown protocol", Code: "UNKNOWN_PROTOCOL"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func tunnelPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrTunnelReset:
+		return FullCheckStatusDto{Msg: "Reset (unlike control)", Code: "RESET"}
+	case ErrTunnelBlackholed:
+		return FullCheckStatusDto{Msg: "Blackholed (unlike control)", Code: "BLACKHOLED"}
+	case ErrTunnelClosed:
+		return FullCheckStatusDto{Msg: "Closed (unlike control)", Code: "CLOSED"}
+	case ErrTunnelUnavailable:
+		return FullCheckStatusDto{Msg: "Endpoint unavailable", Code: "UNAVAILABLE"}
+	case ErrTunnelUnknownProtocol:
+		return FullCheckStatusDto{Msg: "Unknown protocol", Code: "UNKNOWN_PROTOCOL"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckUdpvpnDto(results []UdpvpnResult) FullCheckUdpvpnDto {
+	items := []FullCheckUdpvpnItemDto{}
+	for _, x := range results {
+		item := FullCheckUdpvpnItemDto{Name: x.Name, Host: x.Host, Port: x.Port, Status: FullCheckStatusDto{Msg: "Ok", Code: "OK"}}
+		if x.Err != nil {
+			item.Status = FullCheckStatusDto{Msg: "Lookup error", Code: "LOOKUP_ERR"}
+			items = append(items, item)
+			continue
+		}
+
+		control := udpvpnPrettyResult(x.Control)
+		item.IP = x.Ip.String()
+		item.Control = &control
+		for _, p := range x.Probes {
+			item.Probes = append(item.Probes, FullCheckUdpvpnProbeDto{
+				Protocol: p.Protocol,
+				Result:   udpvpnPrettyResult(p.Err),
+				Verdict:  udpvpnPrettyVerdict(p.Verdict),
+			})
+		}
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(a, b FullCheckUdpvpnItemDto) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return FullCheckUdpvpnDto{Items: items}
+}
+
+func udpvpnPrettyResult(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Replied", Code: "REPLIED"}
+	case inetutil.ErrUdpPortUnreachable:
+		return FullCheckStatusDto{Msg: "ICMP port unreachable", Code: "ICMP"}
+	case inetutil.ErrUdpReadTimeout:
+		return FullCheckStatusDto{Msg: "Silence", Code: "SILENCE"}
+	case ErrUdpvpnUnknownProtocol:
+		return FullCheckStatusDto{Msg: "Unknown protocol", Code: "UNKNOWN_PROTOCOL"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func udpvpnPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrUdpvpnDropped:
+		return FullCheckStatusDto{Msg: "Dropped (unlike control)", Code: "DROPPED"}
+	case ErrUdpvpnNoReaction:
+		return FullCheckStatusDto{Msg: "No reaction (like control)", Code: "NO_REACTION"}
+	case ErrUdpvpnUnavailable:
+		return FullCheckStatusDto{Msg: "Endpoint unavailable", Code: "UNAVAILABLE"}
+	case ErrUdpvpnUnknownProtocol:
+		return FullCheckStatusDto{Msg: "Unknown protocol", Code: "UNKNOWN_PROTOCOL"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckStunDto(results []StunResult) FullCheckStunDto {
+	items := []FullCheckStunItemDto{}
+	for _, x := range results {
+		item := FullCheckStunItemDto{Name: x.Name, Host: x.Host, Port: x.Port, Status: FullCheckStatusDto{Msg: "Ok", Code: "OK"}}
+		if x.Err != nil {
+			item.Status = FullCheckStatusDto{Msg: "Lookup error", Code: "LOOKUP_ERR"}
+			items = append(items, item)
+			continue
+		}
+
+		transport := func(r StunTransportResult) *FullCheckStunTransportDto {
+			dto := &FullCheckStunTransportDto{
+				Status:   stunPrettyTransport(r.Err),
+				RttMs:    r.Rtt.Milliseconds(),
+				Sent:     r.Sent,
+				Received: r.Received,
+				LossPct:  r.Loss(),
+			}
+			if r.Mapped.IsValid() {
+				dto.Mapped = r.Mapped.String()
+			}
+			return dto
+		}
+		verdict := stunPrettyVerdict(x.Verdict)
+		item.IP = x.Ip.String()
+		item.Udp = transport(x.Udp)
+		item.Tcp = transport(x.Tcp)
+		item.Verdict = &verdict
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(a, b FullCheckStunItemDto) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return FullCheckStunDto{Items: items}
+}
+
+func stunPrettyTransport(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case inetutil.ErrUdpReadTimeout, inetutil.ErrTcpReadTimeout:
+		return FullCheckStatusDto{Msg: "Timeout", Code: "TIMEOUT"}
+	case inetutil.ErrUdpPortUnreachable:
+		return FullCheckStatusDto{Msg: "ICMP port unreachable", Code: "ICMP"}
+	case inetutil.ErrTcpConnTimeout:
+		return FullCheckStatusDto{Msg: "Connection timeout", Code: "CONN_TIMEOUT"}
+	case inetutil.ErrTcpConnReset:
+		return FullCheckStatusDto{Msg: "Connection reset", Code: "RESET"}
+	case inetutil.ErrTcpConnClosed:
+		return FullCheckStatusDto{Msg: "Connection closed", Code: "CLOSED"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func stunPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrStunUdpBlocked:
+		return FullCheckStatusDto{Msg: "UDP blocked (tcp is alive)", Code: "UDP_BLOCKED"}
+	case ErrStunUnavailable:
+		return FullCheckStatusDto{Msg: "Server unavailable", Code: "UNAVAILABLE"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func httphostPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrHttpHostReset:
+		return FullCheckStatusDto{Msg: "Connection reset", Code: "RESET"}
+	case ErrHttpHostTimeout:
+		return FullCheckStatusDto{Msg: "Timeout", Code: "TIMEOUT"}
+	case ErrHttpHostRedirect:
+		return FullCheckStatusDto{Msg: "Injected redirect", Code: "INJECTED_REDIRECT"}
+	case ErrHttpHostStub:
+		return FullCheckStatusDto{Msg: "Stub page", Code: "STUB_PAGE"}
+	case ErrHttpHostConnErr:
+		return FullCheckStatusDto{Msg: "Connection error", Code: "CONN_ERR"}
+	default:
+		return FullCheckStatusDto{Msg: "Internal error", Code: "INTERNAL_ERR"}
+	}
+}
+
+func fullCheckQuicItemDto(o QuicGochanOut) FullCheckQuicItemDto {
+	return FullCheckQuicItemDto{
+		Group:       o.Bag.Name,
+		Org:         o.Out.IpInfo.Org,
+		AS:          fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location:    o.Out.IpInfo.CountryIso,
+		IP:          o.Out.IpInfo.Ip.String(),
+		Prefix:      o.Out.IpInfo.Subnet.String(),
+		Sni:         o.Out.Sni,
+		Alive:       webhostPrettyAlive(o.Out.Alive),
+		Quic:        quicPrettyHandshake(o.Out.Quic),
+		QuicVersion: quicPrettyVersion(o.Out.QuicVersion),
+		Verdict:     quicPrettyVerdict(o.Out.Verdict),
+	}
+}
+
+func quicPrettyHandshake(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case inetutil.ErrQuicHandshakeTimeout:
+		return FullCheckStatusDto{Msg: "Timeout", Code: "TIMEOUT"}
+	case inetutil.ErrQuicVersionNegotiation:
+		return FullCheckStatusDto{Msg: "Version negotiation", Code: "VERSION_NEGOTIATION"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func quicPrettyVersion(v quic.Version) string {
+	switch v {
+	case quic.Version1:
+		return "v1"
+	case quic.Version2:
+		return "v2"
+	default:
+		return " — "
+	}
+}
+
+func quicPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrQuicBlocked:
+		return FullCheckStatusDto{Msg: "QUIC blocked", Code: "QUIC_BLOCKED"}
+	case ErrQuicTcpBlocked:
+		return FullCheckStatusDto{Msg: "TCP/TLS blocked", Code: "TCP_BLOCKED"}
+	case ErrQuicNotSupported:
+		return FullCheckStatusDto{Msg: "Not supported by host", Code: "NOT_SUPPORTED_BY_HOST"}
+	case ErrQuicUnavailable:
+		return FullCheckStatusDto{Msg: "Host unavailable", Code: "UNAVAILABLE"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckFingerprintHostDto(o FingerprintGochanOut) FullCheckFingerprintHostDto {
+	x := FullCheckFingerprintHostDto{
+		Group:    o.Bag.Name,
+		Org:      o.Out.IpInfo.Org,
+		AS:       fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location: o.Out.IpInfo.CountryIso,
+		IP:       o.Out.IpInfo.Ip.String(),
+		Prefix:   o.Out.IpInfo.Subnet.String(),
+		Sni:      o.Out.Sni,
+	}
+	for _, item := range o.Out.Items {
+		x.Items = append(x.Items, FullCheckFingerprintItemDto{
+			Fingerprint:  item.Fingerprint,
+			OriginalAlpn: item.OriginalAlpn,
+			ElapsedMs:    item.Elapsed.Milliseconds(),
+			Verdict:      fingerprintPrettyVerdict(item.Verdict),
+		})
+	}
+	return x
+}
+
+func fingerprintPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Pass", Code: "OK"}
+	case ErrFingerprintDropped:
+		return FullCheckStatusDto{Msg: "Dropped", Code: "DROPPED"}
+	case ErrFingerprintThrottled:
+		return FullCheckStatusDto{Msg: "Throttled", Code: "THROTTLED"}
+	case ErrFingerprintUnavailable:
+		return FullCheckStatusDto{Msg: "Host unavailable", Code: "UNAVAILABLE"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckBypassItemDto(o BypassGochanOut) FullCheckBypassItemDto {
+	x := FullCheckBypassItemDto{
+		Group:    o.Bag.Name,
+		Org:      o.Out.IpInfo.Org,
+		AS:       fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location: o.Out.IpInfo.CountryIso,
+		IP:       o.Out.IpInfo.Ip.String(),
+		Prefix:   o.Out.IpInfo.Subnet.String(),
+		Sni:      o.Out.Sni,
+		Baseline: bypassPrettyBaseline(o.Out.Baseline),
+	}
+	for _, item := range o.Out.Strategies {
+		x.Strategies = append(x.Strategies, FullCheckBypassStrategyDto{Strategy: item.Strategy, Status: bypassPrettyStrategy(item.Err)})
+	}
+	return x
+}
+
+func bypassPrettyBaseline(err error) FullCheckStatusDto {
+	if err == nil {
+		return FullCheckStatusDto{Msg: "Not blocked", Code: "OK"}
+	}
+	return FullCheckStatusDto{Msg: err.Error(), Code: "BLOCKED"}
+}
+
+func bypassPrettyStrategy(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Works", Code: "WORKS"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	case ErrBypassUnknownStrategy:
+		return FullCheckStatusDto{Msg: "Unknown strategy", Code: "UNKNOWN_STRATEGY"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckEchItemDto(o EchGochanOut) FullCheckEchItemDto {
+	return FullCheckEchItemDto{
+		Group:    o.Bag.Name,
+		Org:      o.Out.IpInfo.Org,
+		AS:       fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location: o.Out.IpInfo.CountryIso,
+		IP:       o.Out.IpInfo.Ip.String(),
+		Prefix:   o.Out.IpInfo.Subnet.String(),
+		Sni:      o.Out.Sni,
+		Baseline: webhostPrettyAlive(o.Out.Baseline),
+		Ech:      echPrettyHandshake(o.Out.Ech),
+		Verdict:  echPrettyVerdict(o.Out.Verdict),
+	}
+}
+
+func echPrettyHandshake(err error) FullCheckStatusDto {
+	switch {
+	case err == nil:
+		return FullCheckStatusDto{Msg: "Accepted", Code: "ACCEPTED"}
+	case err == ErrEchRetry:
+		return FullCheckStatusDto{Msg: "Accepted after retry", Code: "RETRY"}
+	case errors.Is(err, inetutil.ErrTlsEchRejected):
+		return FullCheckStatusDto{Msg: "Rejected", Code: "REJECTED"}
+	case err == inetutil.ErrTlsHandshakeTimeout:
+		return FullCheckStatusDto{Msg: "Timeout", Code: "TIMEOUT"}
+	case err == ErrEchNoConfig:
+		return FullCheckStatusDto{Msg: "No ECHConfigList", Code: "NO_CONFIG"}
+	case err == ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func echPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrEchBlocked:
+		return FullCheckStatusDto{Msg: "ECH blocked", Code: "ECH_BLOCKED"}
+	case ErrEchDowngraded:
+		return FullCheckStatusDto{Msg: "ECH downgraded", Code: "ECH_DOWNGRADED"}
+	case ErrEchUnavailable:
+		return FullCheckStatusDto{Msg: "Host unavailable", Code: "UNAVAILABLE"}
+	case ErrEchNoConfig:
+		return FullCheckStatusDto{Msg: "No ECHConfigList", Code: "NO_CONFIG"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckIdleHostDto(o IdleGochanOut) FullCheckIdleHostDto {
+	x := FullCheckIdleHostDto{
+		Group:     o.Bag.Name,
+		Org:       o.Out.IpInfo.Org,
+		AS:        fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location:  o.Out.IpInfo.CountryIso,
+		IP:        o.Out.IpInfo.Ip.String(),
+		Prefix:    o.Out.IpInfo.Subnet.String(),
+		Sni:       o.Out.Sni,
+		LongestMs: map[string]int64{},
+	}
+	for keepalive, longest := range o.Out.Longest {
+		x.LongestMs[keepalive] = longest.Milliseconds()
+	}
+	for _, item := range o.Out.Items {
+		x.Items = append(x.Items, FullCheckIdleItemDto{
+			Keepalive:  item.Keepalive,
+			IntervalMs: item.Interval.Milliseconds(),
+			Verdict:    idlePrettyVerdict(item.Verdict),
+		})
+	}
+	return x
+}
+
+func idlePrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Survived", Code: "OK"}
+	case ErrIdleKilled:
+		return FullCheckStatusDto{Msg: "Killed (silently)", Code: "KILLED"}
+	case ErrIdleReset:
+		return FullCheckStatusDto{Msg: "Reset", Code: "RESET"}
+	case ErrIdleServerClosed:
+		return FullCheckStatusDto{Msg: "Closed by server", Code: "SERVER_CLOSED"}
+	case ErrIdleUnavailable:
+		return FullCheckStatusDto{Msg: "Host unavailable", Code: "UNAVAILABLE"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckPortscanHostDto(o PortscanGochanOut) FullCheckPortscanHostDto {
+	x := FullCheckPortscanHostDto{
+		Group:    o.Bag.Name,
+		Org:      o.Out.IpInfo.Org,
+		AS:       fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location: o.Out.IpInfo.CountryIso,
+		IP:       o.Out.IpInfo.Ip.String(),
+		Prefix:   o.Out.IpInfo.Subnet.String(),
+		Sni:      o.Out.Sni,
+		Baseline: FullCheckPortscanItemDto{Port: o.Out.Baseline.Port, Verdict: portscanPrettyVerdict(o.Out.Baseline.Verdict)},
+	}
+	for _, item := range o.Out.Items {
+		x.Items = append(x.Items, FullCheckPortscanItemDto{Port: item.Port, Verdict: portscanPrettyVerdict(item.Verdict)})
+	}
+	return x
+}
+
+func portscanPrettyVerdict(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Open", Code: "OPEN"}
+	case ErrPortscanRefused:
+		return FullCheckStatusDto{Msg: "Refused", Code: "REFUSED"}
+	case ErrPortscanFiltered:
+		return FullCheckStatusDto{Msg: "Filtered (timeout)", Code: "FILTERED"}
+	case ErrPortscanReset:
+		return FullCheckStatusDto{Msg: "Reset", Code: "RESET"}
+	case ErrPortscanUnavailable:
+		return FullCheckStatusDto{Msg: "Host unavailable", Code: "UNAVAILABLE"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func fullCheckDnsinjectHostDto(o DnsinjectGochanOut) FullCheckDnsinjectHostDto {
+	x := FullCheckDnsinjectHostDto{
+		Group:    o.Bag.Name,
+		Org:      o.Out.IpInfo.Org,
+		AS:       fmt.Sprintf("AS%d", o.Out.IpInfo.Asn),
+		Location: o.Out.IpInfo.CountryIso,
+		IP:       o.Out.IpInfo.Ip.String(),
+		Prefix:   o.Out.IpInfo.Subnet.String(),
+		Verdict:  dnsinjectPrettyVerdict(o.Out.Verdict),
+	}
+	for _, item := range o.Out.Controls {
+		x.Controls = append(x.Controls, fullCheckDnsinjectItemDto(item))
+	}
+	for _, item := range o.Out.Items {
+		x.Items = append(x.Items, fullCheckDnsinjectItemDto(item))
+	}
+	return x
+}
+
+func fullCheckDnsinjectItemDto(item DnsinjectItem) FullCheckDnsinjectItemDto {
+	x := FullCheckDnsinjectItemDto{Domain: item.Domain, Verdict: dnsinjectPrettyVerdict(item.Verdict)}
+	if item.Err == nil {
+		x.Rcode = item.Rcode.String()
+	}
+	for _, a := range item.Answers {
+		x.Answers = append(x.Answers, FullCheckDnsinjectAnswerDto{
+			IP:       a.IpInfo.Ip.String(),
+			Org:      a.IpInfo.Org,
+			AS:       fmt.Sprintf("AS%d", a.IpInfo.Asn),
+			Location: a.IpInfo.CountryIso,
+			Ttl:      a.Ttl,
+		})
+	}
+	return x
+}
+
+func dnsinjectPrettyVerdict(err error) FullCheckStatusDto {
+	if inetutil.IsInetutilErr(err) {
+		return FullCheckStatusDto{Msg: err.Error(), Code: "INETUTIL_ERR"}
+	}
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Not detected", Code: "OK"}
+	case ErrDnsinjectInjected:
+		return FullCheckStatusDto{Msg: "Injected answer", Code: "INJECTED"}
+	case ErrDnsinjectNotSilent:
+		return FullCheckStatusDto{Msg: "IP is not silent", Code: "NOT_SILENT"}
+	case ErrDnsSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func webhostPrettyAlive(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case inetutil.ErrHttpMalformedResponse:
+		return FullCheckStatusDto{Msg: "Ok, custom HTTP", Code: "OK_CUSTOM_HTTP"}
+	}
+	if blockpage, ok := errors.AsType[*WebhostBlockpageError](err); ok {
+		return FullCheckStatusDto{Msg: "Blocked by stub page: " + blockpage.Isp, Code: "BLOCKPAGE"}
+	}
+	return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+}
+
+func webhostPrettySniMatrixItem(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "Ok", Code: "OK"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	}
+	return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+}
+
+func webhostPrettyTlsV(v uint16) string {
+	switch v {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
 		return "1.2"
 	case tls.VersionTLS13:
 		return "1.3"
@@ -403,13 +1666,52 @@ func webhostPrettyTcp1620(err error) FullCheckStatusDto {
 		return FullCheckStatusDto{Msg: "Detected", Code: "DETECTED"}
 	case ErrWebhostSkip:
 		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
-	case inetutil.ErrTlsWriteBrokenPipe:
+	case inetutil.ErrTlsWriteBrokenPipe, ErrWebhostNotEnoughRx:
 		return FullCheckStatusDto{Msg: "Not supported by host", Code: "NOT_SUPPORTED_BY_HOST"}
 	default:
 		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
 	}
 }
 
+func webhostPrettySeries(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "No", Code: "OK"}
+	case ErrWebhostSeriesFrozen:
+		return FullCheckStatusDto{Msg: "Frozen", Code: "FROZEN"}
+	case ErrWebhostSeriesThrottled:
+		return FullCheckStatusDto{Msg: "Throttled", Code: "THROTTLED"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func webhostPrettyL4_25(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "No", Code: "OK"}
+	case inetutil.ErrTcpWriteTimeout, inetutil.ErrTcpReadTimeout:
+		return FullCheckStatusDto{Msg: "Detected", Code: "DETECTED"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
+func webhostPrettyPqKeyShare(err error) FullCheckStatusDto {
+	switch err {
+	case nil:
+		return FullCheckStatusDto{Msg: "No", Code: "OK"}
+	case ErrWebhostPqBlocked:
+		return FullCheckStatusDto{Msg: "Detected", Code: "DETECTED"}
+	case ErrWebhostSkip:
+		return FullCheckStatusDto{Msg: "Skipped", Code: "SKIP"}
+	default:
+		return FullCheckStatusDto{Msg: err.Error(), Code: "ERR"}
+	}
+}
+
 func webhostPrettySiberian(err error) FullCheckStatusDto {
 	switch err {
 	case nil:
@@ -0,0 +1,181 @@
+// Checks if a censor blocks cleartext http (port 80) by the Host header: requests with the real,
+// a random and a case-mangled Host are sent to the same ip, and the responses are compared.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type HttpHostOpt struct {
+	Ctx    context.Context
+	Target string // real hostname
+}
+
+type HttpHostProbe struct {
+	Host       string
+	HttpStatus int
+	Location   string
+	Verdict    error
+}
+
+type HttpHostResult struct {
+	Target  string
+	Ip      netip.Addr
+	Real    HttpHostProbe
+	Random  HttpHostProbe
+	Mangled HttpHostProbe
+	Err     error // lookup error; probes are not performed
+}
+
+var (
+	ErrHttpHostReset    = errors.New("httphost: connection reset")
+	ErrHttpHostTimeout  = errors.New("httphost: timeout")
+	ErrHttpHostRedirect = errors.New("httphost: injected redirect")
+	ErrHttpHostStub     = errors.New("httphost: stub page")
+	ErrHttpHostConnErr  = errors.New("httphost: connection error")
+	ErrHttpHostLookup   = errors.New("httphost: lookup error")
+	ErrHttpHostInternal = errors.New("httphost: internal error")
+)
+
+// Resolves the target once and probes its ip with the real, a random and a case-mangled Host header.
+func HttpHost(opt HttpHostOpt) HttpHostResult {
+	cfg := config.Get().Checkers.HttpHost
+	res := HttpHostResult{Target: opt.Target}
+
+	lookupCtx, cancel := context.WithTimeout(opt.Ctx, cfg.Timeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupNetIP(lookupCtx, "ip4", opt.Target)
+	if err != nil || len(ips) == 0 {
+		log.Println("httphost/lookup", opt.Target, err)
+		res.Err = ErrHttpHostLookup
+		return res
+	}
+	res.Ip = ips[0].Unmap()
+
+	rndHostname, _ := randomHostname()
+	res.Real = httpHostProbe(opt.Ctx, res.Ip, opt.Target, opt.Target)
+	res.Random = httpHostProbe(opt.Ctx, res.Ip, opt.Target, rndHostname)
+	res.Mangled = httpHostProbe(opt.Ctx, res.Ip, opt.Target, httpHostMangle(opt.Target))
+	return res
+}
+
+func httpHostProbe(ctx context.Context, ip netip.Addr, target, host string) HttpHostProbe {
+	cfg := config.Get().Checkers.HttpHost
+	probe := HttpHostProbe{Host: host}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	conn, err := inetutil.GetTcpConn(inetutil.TcpConnOpt{Ctx: ctx, Ip: ip, Port: cfg.Port})
+	if err != nil {
+		probe.Verdict = httpHostInetutilVerdict(err)
+		return probe
+	}
+	defer conn.Close()
+
+	req, err := http.NewRequest("GET", "http://"+target+"/", http.NoBody)
+	if err != nil {
+		probe.Verdict = ErrHttpHostInternal
+		return probe
+	}
+	req.Host = host // written as is (incl. case)
+	req.Close = true
+	inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+
+	if _, err := inetutil.TcpWriteHttpRequest(ctx, conn, req); err != nil {
+		probe.Verdict = httpHostInetutilVerdict(err)
+		return probe
+	}
+
+	resp, err := inetutil.TcpReadHttpResponse(ctx, conn, bufio.NewReader(conn))
+	if err != nil {
+		probe.Verdict = httpHostInetutilVerdict(err)
+		return probe
+	}
+	// the body is not closed: it would be drained without a deadline (the connection is closed anyway)
+	probe.HttpStatus = resp.StatusCode
+	probe.Location = resp.Header.Get("Location")
+
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 && httpHostInjectedRedirect(probe.Location, target, host) {
+		probe.Verdict = ErrHttpHostRedirect
+		return probe
+	}
+
+	// the stub page may be cut off by a reset, so what has been read is still checked
+	var body strings.Builder
+	_, err = inetutil.TcpReadHttpBody(ctx, conn, io.LimitReader(io.TeeReader(resp.Body, &body), int64(cfg.MaxBodyBytes)))
+	if resp.StatusCode == http.StatusUnavailableForLegalReasons || httpHostStub(body.String()) {
+		probe.Verdict = ErrHttpHostStub
+		return probe
+	}
+	if err != nil {
+		probe.Verdict = httpHostInetutilVerdict(err)
+	}
+
+	return probe
+}
+
+// A redirect is considered injected if it leads neither to the target nor to the requested host.
+func httpHostInjectedRedirect(location, target, host string) bool {
+	u, err := url.Parse(location)
+	if err != nil {
+		return true
+	}
+	if u.Hostname() == "" { // relative
+		return false
+	}
+
+	norm := func(h string) string { return strings.TrimPrefix(strings.ToLower(h), "www.") }
+	locHost := norm(u.Hostname())
+	return locHost != norm(target) && locHost != norm(host)
+}
+
+func httpHostStub(body string) bool {
+	cfg := config.Get().Checkers.HttpHost
+	body = strings.ToLower(body)
+	for _, s := range cfg.StubSignatures {
+		if s != "" && strings.Contains(body, strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns hostname with alternating letter case (e.g. ExAmPlE.CoM).
+func httpHostMangle(host string) string {
+	b := []byte(strings.ToLower(host))
+	upper := true
+	for i, c := range b {
+		if c >= 'a' && c <= 'z' {
+			if upper {
+				b[i] = c - 'a' + 'A'
+			}
+			upper = !upper
+		}
+	}
+	return string(b)
+}
+
+func httpHostInetutilVerdict(err error) error {
+	switch err {
+	case inetutil.ErrTcpConnReset:
+		return ErrHttpHostReset
+	case inetutil.ErrTcpConnTimeout, inetutil.ErrTcpReadTimeout, inetutil.ErrTcpWriteTimeout:
+		return ErrHttpHostTimeout
+	}
+	log.Println("httphost", err)
+	return ErrHttpHostConnErr
+}
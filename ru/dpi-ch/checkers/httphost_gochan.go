@@ -0,0 +1,27 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/gochan"
+)
+
+func HttpHostGochan(ctx context.Context) <-chan HttpHostResult {
+	cfg := config.Get().Checkers.HttpHost
+	in := make(chan HttpHostOpt)
+	out := gochan.Start(gochan.GochanOpt[HttpHostOpt, HttpHostResult]{
+		Ctx:      ctx,
+		Workers:  cfg.Workers,
+		Input:    in,
+		Executor: HttpHost,
+	})
+
+	items := []HttpHostOpt{}
+	for _, t := range cfg.Targets {
+		items = append(items, HttpHostOpt{Ctx: ctx, Target: t})
+	}
+
+	gochan.Push(ctx, in, items)
+	return out
+}
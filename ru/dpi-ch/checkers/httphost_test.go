@@ -0,0 +1,64 @@
+package checkers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// The local server plays both the origin and the censor, depending on the Host header.
+func TestHttpHostProbe(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.HttpHost
+	cfg.Timeout = 300 * time.Millisecond
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.ToLower(r.Host) {
+		case "redirect.test":
+			http.Redirect(w, r, "http://warning.rt.ru/", http.StatusFound)
+		case "own-redirect.test":
+			http.Redirect(w, r, "http://www.own-redirect.test/", http.StatusMovedPermanently)
+		case "stub.test":
+			w.Write([]byte("<a href=\"https://eais.rkn.gov.ru/\">blocked</a>"))
+		case "451.test":
+			w.WriteHeader(http.StatusUnavailableForLegalReasons)
+		case "reset.test":
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		case "stall.test":
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+	cfg.Port = int(addr.Port())
+
+	cases := []struct {
+		target, host string
+		want         error
+	}{
+		{"ok.test", "ok.test", nil},
+		{"ok.test", httpHostMangle("ok.test"), nil},
+		{"own-redirect.test", "own-redirect.test", nil},
+		{"redirect.test", "redirect.test", ErrHttpHostRedirect},
+		{"stub.test", "stub.test", ErrHttpHostStub},
+		{"451.test", "451.test", ErrHttpHostStub},
+		{"reset.test", "reset.test", ErrHttpHostReset},
+		{"stall.test", "stall.test", ErrHttpHostTimeout},
+	}
+	for _, c := range cases {
+		if probe := httpHostProbe(context.Background(), addr.Addr(), c.target, c.host); probe.Verdict != c.want {
+			t.Errorf("%s (host %s): got %v, want %v", c.target, c.host, probe.Verdict, c.want)
+		}
+	}
+}
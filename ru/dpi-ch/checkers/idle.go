@@ -0,0 +1,202 @@
+// Checks if a censor kills long-lived tls connections (it breaks vpn tunnels and push services): connections are held
+// open for several idle intervals (in parallel) with different keepalive patterns, and a request is sent after each one.
+// If the connection survives with http keepalive, but not without it, it is an idle timeout; otherwise a lifetime limit.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+
+	tls "github.com/refraction-networking/utls"
+)
+
+type IdleSingleOpt struct {
+	Ctx  context.Context
+	Ip   netip.Addr
+	Port int
+	Sni  string
+	Host string
+}
+
+type IdleItem struct {
+	Keepalive string
+	Interval  time.Duration
+	Err       error // of the handshake or the request after the interval
+	Verdict   error
+}
+
+type IdleSingleResult struct {
+	IpInfo  inetlookup.IpInfo
+	Port    int
+	Sni     string
+	Items   []IdleItem
+	Longest map[string]time.Duration // by keepalive; the longest interval that survived (0 is none)
+}
+
+var (
+	ErrIdleKilled           = errors.New("idle: killed (silently)")
+	ErrIdleReset            = errors.New("idle: reset")
+	ErrIdleServerClosed     = errors.New("idle: closed by server")
+	ErrIdleUnavailable      = errors.New("idle: host unavailable")
+	ErrIdleUnknownKeepalive = errors.New("idle: unknown keepalive")
+)
+
+const (
+	IdleKeepaliveNone = "none" // no traffic at all
+	IdleKeepaliveTcp  = "tcp"  // tcp keepalive probes (no payload, invisible to the server app)
+	IdleKeepaliveHttp = "http" // http requests (i.e. tls records with payload)
+)
+
+// All keepalive/interval pairs are held in parallel, each one in its own connection.
+func IdleSingle(opt IdleSingleOpt) IdleSingleResult {
+	cfg := config.Get().Checkers.Idle
+	res := IdleSingleResult{
+		IpInfo:  inetlookup.Default().IpInfo(opt.Ip),
+		Port:    opt.Port,
+		Sni:     opt.Sni,
+		Longest: map[string]time.Duration{},
+	}
+
+	for _, keepalive := range cfg.Keepalives {
+		res.Longest[keepalive] = 0
+		for _, interval := range cfg.Intervals {
+			res.Items = append(res.Items, IdleItem{Keepalive: keepalive, Interval: interval})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range res.Items {
+		wg.Go(func() {
+			item := &res.Items[i]
+			item.Err = idleCheck(opt, item.Keepalive, item.Interval)
+			item.Verdict = idleVerdict(item.Err)
+		})
+	}
+	wg.Wait()
+
+	for _, x := range res.Items {
+		if x.Verdict == nil && x.Interval > res.Longest[x.Keepalive] {
+			res.Longest[x.Keepalive] = x.Interval
+		}
+	}
+
+	log.Println("idle; ip:", opt.Ip, "sni:", opt.Sni, "longest:", res.Longest)
+	return res
+}
+
+func idleCheck(opt IdleSingleOpt, keepalive string, interval time.Duration) error {
+	cfg := config.Get().Checkers.Idle
+	if keepalive != IdleKeepaliveNone && keepalive != IdleKeepaliveTcp && keepalive != IdleKeepaliveHttp {
+		log.Println("idle; unknown keepalive:", keepalive)
+		return ErrIdleUnknownKeepalive
+	}
+
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(inetutil.TlsConnOpt{
+		Ctx:                 opt.Ctx,
+		Ip:                  opt.Ip,
+		Port:                opt.Port,
+		Sni:                 opt.Sni,
+		TcpConnTimeout:      cfg.TcpConnTimeout,
+		TlsHandshakeTimeout: cfg.TlsHandshakeTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	defer tlsConn.Close()
+
+	br := bufio.NewReader(tlsConn)
+	if keepalive == IdleKeepaliveTcp {
+		if tcpConn, ok := tlsConn.NetConn().(*net.TCPConn); ok {
+			tcpConn.SetKeepAliveConfig(net.KeepAliveConfig{Enable: true, Idle: cfg.KeepalivePeriod, Interval: cfg.KeepalivePeriod, Count: 3})
+		}
+	}
+
+	deadline := time.After(interval)
+	period := time.NewTicker(cfg.KeepalivePeriod)
+	defer period.Stop()
+	for {
+		select {
+		case <-opt.Ctx.Done():
+			return ErrWebhostSkip
+		case <-period.C:
+			if keepalive == IdleKeepaliveHttp {
+				if err := idleRequest(opt, tlsConn, br, false); err != nil {
+					return err
+				}
+			}
+			continue
+		case <-deadline:
+		}
+		break
+	}
+
+	return idleRequest(opt, tlsConn, br, true)
+}
+
+func idleRequest(opt IdleSingleOpt, tlsConn *tls.UConn, br *bufio.Reader, last bool) error {
+	cfg := config.Get().Checkers.Idle
+
+	req, err := http.NewRequest("HEAD", "https://"+opt.Host, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Close = last
+	inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+
+	ctx, cancel := context.WithTimeout(opt.Ctx, cfg.ReadTimeout)
+	defer cancel()
+	if _, err := inetutil.TlsWriteHttpRequest(ctx, tlsConn, req); err != nil {
+		return err
+	}
+
+	// a close by the server is a verdict of its own here, while inetutil doesn't tell a bare eof from other errors
+	if _, err := idlePeek(ctx, tlsConn, br); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return inetutil.ErrTcpConnClosed
+	}
+
+	resp, err := inetutil.TlsReadHttpResponse(ctx, tlsConn, br)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Waits for the first byte of the response (until the ctx deadline); other errors are left to the response read.
+func idlePeek(ctx context.Context, tlsConn *tls.UConn, br *bufio.Reader) ([]byte, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		tlsConn.SetReadDeadline(deadline)
+		defer tlsConn.SetReadDeadline(time.Time{})
+	}
+	return br.Peek(1)
+}
+
+func idleVerdict(err error) error {
+	switch err {
+	case nil, inetutil.ErrHttpMalformedResponse:
+		return nil
+	case ErrWebhostSkip, ErrIdleUnknownKeepalive:
+		return err
+	case inetutil.ErrTcpReadTimeout, inetutil.ErrTcpWriteTimeout:
+		return ErrIdleKilled
+	case inetutil.ErrTcpConnReset, inetutil.ErrTlsWriteBrokenPipe:
+		return ErrIdleReset
+	case inetutil.ErrTcpConnClosed:
+		return ErrIdleServerClosed
+	default:
+		return ErrIdleUnavailable
+	}
+}
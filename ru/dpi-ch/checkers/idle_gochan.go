@@ -0,0 +1,34 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+type IdleGochanOut struct {
+	Bag WebhostGochanBag
+	Out IdleSingleResult
+}
+
+type IdleGochanRunnerOut struct {
+	Out      <-chan IdleGochanOut
+	Progress <-chan string
+}
+
+func IdleGochanRunner(ctx context.Context) IdleGochanRunnerOut {
+	cfg := config.Get().Checkers.Idle
+	out, progress := webhostFarmRunner(webhostFarmRunnerOpt[IdleGochanOut]{
+		Ctx:     ctx,
+		Targets: cfg.Targets,
+		Name:    "idle",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) IdleGochanOut {
+			return IdleGochanOut{
+				Bag: bag,
+				Out: IdleSingle(IdleSingleOpt{Ctx: in.Ctx, Ip: in.Ip, Port: in.Port, Sni: in.Sni, Host: in.Host}),
+			}
+		},
+	})
+	return IdleGochanRunnerOut{Out: out, Progress: progress}
+}
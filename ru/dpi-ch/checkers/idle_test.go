@@ -0,0 +1,46 @@
+package checkers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// The local server closes idle connections by itself (like many push and api servers do), so only http keepalive survives.
+func TestIdleServerClosed(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.Idle
+	cfg.KeepalivePeriod = 100 * time.Millisecond
+	cfg.ReadTimeout = time.Second
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.Config.ReadHeaderTimeout = 300 * time.Millisecond // the first request
+	srv.Config.IdleTimeout = 300 * time.Millisecond
+	srv.StartTLS()
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	opt := IdleSingleOpt{Ctx: context.Background(), Ip: addr.Addr(), Port: int(addr.Port()), Sni: "example.com", Host: "example.com"}
+	cases := []struct {
+		keepalive string
+		interval  time.Duration
+		want      error
+	}{
+		{IdleKeepaliveNone, 100 * time.Millisecond, nil},
+		{IdleKeepaliveNone, 600 * time.Millisecond, ErrIdleServerClosed},
+		{IdleKeepaliveHttp, 600 * time.Millisecond, nil},
+		{"ping", 100 * time.Millisecond, ErrIdleUnknownKeepalive},
+	}
+	for _, c := range cases {
+		if got := idleVerdict(idleCheck(opt, c.keepalive, c.interval)); got != c.want {
+			t.Errorf("keepalive %s, interval %s: got %v, want %v", c.keepalive, c.interval, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Checks for "l4-25" restrictions (the successor of tcp 16-20): the censor counts packets rather than bytes,
+// so a small payload sent in tiny chunks with delays is enough to trigger the restriction.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type L4_25Opt struct {
+	Ctx        context.Context
+	TlsConnOpt inetutil.TlsConnOpt
+	Host       string
+}
+
+// Returns nil if the host responded after receiving the whole chunked payload.
+// ErrTcpWriteTimeout/ErrTcpReadTimeout mean that the connection was frozen (i.e. restriction detected).
+func L4_25(opt L4_25Opt) error {
+	cfg := config.Get().Checkers.Webhost
+	if opt.Ctx == nil {
+		opt.Ctx = context.Background()
+	}
+
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(opt.TlsConnOpt)
+	if err != nil {
+		return err
+	}
+	defer tlsConn.Close()
+
+	req := l4_25Request(opt.Host, cfg.L4_25nBytes)
+
+	// The whole payload is sent slowly, so the write timeout must take the delays into account.
+	// The i/o timeouts are not derived from opt.Ctx: a cancel must not look like a frozen connection.
+	chunks := (len(req) + max(cfg.L4_25chunkSize, 1) - 1) / max(cfg.L4_25chunkSize, 1)
+	writeTimeout := cfg.TcpWriteTimeout + cfg.L4_25chunkDelay*time.Duration(chunks)
+	writeCtx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	_, err = inetutil.TlsWriteChunked(writeCtx, tlsConn, req, cfg.L4_25chunkSize, cfg.L4_25chunkDelay)
+	if opt.Ctx.Err() != nil {
+		return ErrWebhostSkip
+	}
+	if err != nil {
+		return err
+	}
+
+	readCtx, cancel := context.WithTimeout(context.Background(), cfg.L4_25respTimeout)
+	defer cancel()
+	resp, err := inetutil.TlsReadHttpResponse(readCtx, tlsConn, bufio.NewReader(tlsConn))
+	if opt.Ctx.Err() != nil {
+		return ErrWebhostSkip
+	}
+	if err != nil && err != inetutil.ErrHttpMalformedResponse {
+		log.Println("webhost; l4-25 ip:", opt.TlsConnOpt.Ip, "port:", opt.TlsConnOpt.Port, "err:", err)
+		return err
+	}
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	return nil
+}
+
+// Returns a raw http request of exactly n bytes (if possible): headers + random body.
+func l4_25Request(host string, n int) []byte {
+	headers := func(bodyLen int) string {
+		return fmt.Sprintf("POST / HTTP/1.1\r\nHost: %s\r\nContent-Length: %d\r\n\r\n", host, bodyLen)
+	}
+
+	// Content-Length affects the size of the headers, so we look for a fixed point.
+	// It may not exist on the digit boundary (off by one byte), so the number of iterations is limited.
+	bodyLen := n
+	for range 8 {
+		x := max(0, n-len(headers(bodyLen)))
+		if x == bodyLen {
+			break
+		}
+		bodyLen = x
+	}
+
+	body, _ := randomBytes(bodyLen)
+	return append([]byte(headers(bodyLen)), body...)
+}
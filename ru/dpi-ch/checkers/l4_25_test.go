@@ -0,0 +1,62 @@
+package checkers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+func TestL4_25(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.Webhost
+	cfg.L4_25chunkDelay = 5 * time.Millisecond
+	cfg.L4_25respTimeout = 300 * time.Millisecond
+
+	// the request line is always the same, so the host picks the behavior
+	complete := make(chan bool, 1)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Host == "stall.example.com" {
+			<-r.Context().Done()
+			return
+		}
+		complete <- r.ContentLength > 0 && int64(len(body)) == r.ContentLength
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	l4_25 := func(ctx context.Context, host string) error {
+		tlsConnOpt := inetutil.TlsConnOpt{Ip: addr.Addr(), Port: int(addr.Port()), Sni: "example.com"}
+		return L4_25(L4_25Opt{Ctx: ctx, TlsConnOpt: tlsConnOpt, Host: host})
+	}
+
+	cancelled, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond) // in the middle of the slow write
+	defer cancel()
+	cases := []struct {
+		name string
+		ctx  context.Context
+		host string
+		want error
+	}{
+		{"pass", context.Background(), "example.com", nil},
+		{"stall", context.Background(), "stall.example.com", inetutil.ErrTcpReadTimeout},
+		{"cancel", cancelled, "stall.example.com", ErrWebhostSkip},
+	}
+	for _, c := range cases {
+		if err := l4_25(c.ctx, c.host); err != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.want)
+		}
+		if c.want == nil && !<-complete {
+			t.Errorf("%s: the chunked payload is incomplete", c.name)
+		}
+	}
+}
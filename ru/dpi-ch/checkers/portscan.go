@@ -0,0 +1,115 @@
+// Checks if a censor filters tcp by the destination port: every port from the list is probed on the same ip
+// as the baseline port (the one the host is farmed by), and classified as open, refused, filtered or reset.
+// For meaningful results, controlled targets (e.g. your own vps that listens on every port) should be used.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/netip"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type PortscanSingleOpt struct {
+	Ctx  context.Context
+	Ip   netip.Addr
+	Port int // baseline
+	Sni  string
+}
+
+type PortscanItem struct {
+	Port    int
+	Err     error // nil is open; otherwise inetutil error (e.g. refused, conn timeout, reset)
+	Verdict error
+}
+
+type PortscanSingleResult struct {
+	IpInfo   inetlookup.IpInfo
+	Sni      string
+	Baseline PortscanItem
+	Items    []PortscanItem
+}
+
+var (
+	ErrPortscanRefused     = errors.New("portscan: refused")
+	ErrPortscanFiltered    = errors.New("portscan: filtered (timeout)")
+	ErrPortscanReset       = errors.New("portscan: reset")
+	ErrPortscanUnavailable = errors.New("portscan: host unavailable (baseline port is not open)")
+)
+
+// The baseline port is probed first; if it is not open, the ports are not probed at all.
+func PortscanSingle(opt PortscanSingleOpt) PortscanSingleResult {
+	cfg := config.Get().Checkers.Portscan
+	res := PortscanSingleResult{
+		IpInfo: inetlookup.Default().IpInfo(opt.Ip),
+		Sni:    opt.Sni,
+	}
+
+	res.Baseline.Port = opt.Port
+	res.Baseline.Err = portscanProbe(opt.Ctx, opt.Ip, opt.Port, opt.Sni)
+	res.Baseline.Verdict = portscanVerdict(res.Baseline.Err)
+
+	for _, port := range cfg.Ports {
+		item := PortscanItem{Port: port}
+		switch {
+		case opt.Ctx.Err() != nil:
+			item.Err, item.Verdict = ErrWebhostSkip, ErrWebhostSkip
+		case res.Baseline.Verdict != nil:
+			item.Err, item.Verdict = ErrWebhostSkip, ErrPortscanUnavailable
+		default:
+			item.Err = portscanProbe(opt.Ctx, opt.Ip, port, opt.Sni)
+			item.Verdict = portscanVerdict(item.Err)
+		}
+		res.Items = append(res.Items, item)
+	}
+
+	log.Println("portscan; ip:", opt.Ip, "baseline:", res.Baseline, "items:", res.Items)
+	return res
+}
+
+// Connects to the port and sends a tls hello as the first flight: a censor may let the tcp handshake through
+// and reset the connection only when it sees the data.
+func portscanProbe(ctx context.Context, ip netip.Addr, port int, sni string) error {
+	cfg := config.Get().Checkers.Portscan
+	conn, err := inetutil.GetTcpConn(inetutil.TcpConnOpt{Ctx: ctx, Ip: ip, Port: port, TcpConnTimeout: cfg.TcpConnTimeout})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	fingerprint := inetutil.Fingerprints[config.Get().InetUtil.Fingerprint]
+	if fingerprint == nil {
+		fingerprint = inetutil.Fingerprints["chrome"]
+	}
+	hello, err := inetutil.UTlsHelloRecord(sni, *fingerprint)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ReadTimeout)
+	defer cancel()
+	if _, err := inetutil.TcpWriteRead(ctx, conn, hello); err == inetutil.ErrTcpConnReset {
+		return err
+	}
+	return nil // the port is open, whatever the service answers (or not) to the hello
+}
+
+func portscanVerdict(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case inetutil.ErrTcpConnRefused:
+		return ErrPortscanRefused
+	case inetutil.ErrTcpConnTimeout:
+		return ErrPortscanFiltered
+	case inetutil.ErrTcpConnReset:
+		return ErrPortscanReset
+	default:
+		return err
+	}
+}
@@ -0,0 +1,34 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+type PortscanGochanOut struct {
+	Bag WebhostGochanBag
+	Out PortscanSingleResult
+}
+
+type PortscanGochanRunnerOut struct {
+	Out      <-chan PortscanGochanOut
+	Progress <-chan string
+}
+
+func PortscanGochanRunner(ctx context.Context) PortscanGochanRunnerOut {
+	cfg := config.Get().Checkers.Portscan
+	out, progress := webhostFarmRunner(webhostFarmRunnerOpt[PortscanGochanOut]{
+		Ctx:     ctx,
+		Targets: cfg.Targets,
+		Name:    "portscan",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) PortscanGochanOut {
+			return PortscanGochanOut{
+				Bag: bag,
+				Out: PortscanSingle(PortscanSingleOpt{Ctx: in.Ctx, Ip: in.Ip, Port: in.Port, Sni: in.Sni}),
+			}
+		},
+	})
+	return PortscanGochanRunnerOut{Out: out, Progress: progress}
+}
@@ -0,0 +1,56 @@
+package checkers
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+func TestPortscanProbe(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	config.Get().Checkers.Portscan.ReadTimeout = 300 * time.Millisecond
+
+	cases := map[string]struct {
+		handle func(conn *net.TCPConn) // reacts to the first flight; nil is a closed port
+		want   error
+	}{
+		"answered": {func(conn *net.TCPConn) { conn.Write([]byte("pong")) }, nil},
+		"closed":   {func(conn *net.TCPConn) {}, nil},
+		"silent":   {func(conn *net.TCPConn) { io.Copy(io.Discard, conn) }, nil},
+		"reset":    {func(conn *net.TCPConn) { conn.SetLinger(0) }, ErrPortscanReset},
+		"refused":  {nil, ErrPortscanRefused},
+	}
+	for name, tt := range cases {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := ln.Addr().(*net.TCPAddr).AddrPort()
+		if tt.handle == nil {
+			ln.Close()
+		} else {
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				if _, err := conn.Read(make([]byte, 4096)); err == nil {
+					tt.handle(conn.(*net.TCPConn))
+				}
+			}()
+		}
+
+		err = portscanProbe(context.Background(), addr.Addr(), int(addr.Port()), "example.com")
+		ln.Close()
+		if got := portscanVerdict(err); got != tt.want {
+			t.Fatalf("%s: got %v, want %v", name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Checks if quic (http/3 over udp) is restricted separately from tcp: a quic handshake is compared
+// with the tcp/tls alive result for the same ip and sni.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/netip"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetlookup"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+	"github.com/quic-go/quic-go"
+)
+
+type QuicSingleOpt struct {
+	Ctx  context.Context
+	Ip   netip.Addr
+	Port int
+	Sni  string
+	Host string
+}
+
+type QuicSingleResult struct {
+	IpInfo inetlookup.IpInfo
+	Port   int
+	Sni    string
+	Alive  error // tcp/tls
+	Quic   error
+	// Set only if Quic == nil
+	QuicVersion quic.Version
+	Verdict     error
+}
+
+var (
+	ErrQuicBlocked      = errors.New("quic: blocked (tcp/tls is alive)")
+	ErrQuicTcpBlocked   = errors.New("quic: tcp/tls is blocked (quic is alive)")
+	ErrQuicNotSupported = errors.New("quic: version not supported by host")
+	ErrQuicUnavailable  = errors.New("quic: host unavailable")
+)
+
+func QuicSingle(opt QuicSingleOpt) QuicSingleResult {
+	cfg := config.Get().Checkers.Quic
+	webhostCfg := config.Get().Checkers.Webhost
+	res := QuicSingleResult{
+		IpInfo: inetlookup.Default().IpInfo(opt.Ip),
+		Port:   opt.Port,
+		Sni:    opt.Sni,
+	}
+
+	quicConn, err := inetutil.GetHandshakedQuicConn(inetutil.QuicConnOpt{
+		Ctx:              opt.Ctx,
+		Ip:               opt.Ip,
+		Port:             opt.Port,
+		Sni:              opt.Sni,
+		HandshakeTimeout: cfg.HandshakeTimeout,
+		Versions:         quicVersions(cfg.Versions),
+		Alpn:             cfg.Alpn,
+	})
+	if err == nil {
+		res.QuicVersion = quicConn.ConnectionState().Version
+		quicConn.Close()
+	}
+	res.Quic = err
+
+	webhostOpt := WebhostSingleOpt{Ctx: opt.Ctx, Ip: opt.Ip, Port: opt.Port, Sni: opt.Sni, Host: opt.Host}
+	tlsConn, err := webhostHandshakesCheck(webhostOpt, inetutil.TlsConnOpt{
+		Ip:                  opt.Ip,
+		Port:                opt.Port,
+		Sni:                 opt.Sni,
+		TcpConnTimeout:      webhostCfg.TcpConnTimeout,
+		TlsHandshakeTimeout: webhostCfg.TlsHandshakeTimeout,
+	})
+	if err == nil {
+		_, err = webhostAliveCheck(webhostOpt, tlsConn)
+	} else if tlsConn != nil {
+		tlsConn.Close() // with a fallback sni
+	}
+	res.Alive = err
+
+	res.Verdict = quicVerdict(res.Quic, res.Alive)
+	log.Println("quic; ip:", opt.Ip, "sni:", opt.Sni, "quic:", res.Quic, "alive:", res.Alive)
+	return res
+}
+
+func quicVerdict(quicErr, aliveErr error) error {
+	alive := aliveErr == nil || aliveErr == inetutil.ErrHttpMalformedResponse
+	switch {
+	case quicErr == inetutil.ErrQuicVersionNegotiation:
+		return ErrQuicNotSupported
+	case quicErr == nil && alive:
+		return nil
+	case quicErr == nil:
+		return ErrQuicTcpBlocked
+	case alive:
+		return ErrQuicBlocked
+	default:
+		return ErrQuicUnavailable
+	}
+}
+
+// Config versions are 1 (rfc 9000) and 2 (rfc 9369); empty is both.
+func quicVersions(versions []int) []quic.Version {
+	out := []quic.Version{}
+	for _, v := range versions {
+		switch v {
+		case 1:
+			out = append(out, quic.Version1)
+		case 2:
+			out = append(out, quic.Version2)
+		default:
+			log.Println("quic; unsupported version ignored:", v)
+		}
+	}
+	return out
+}
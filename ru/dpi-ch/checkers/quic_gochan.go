@@ -0,0 +1,34 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+type QuicGochanOut struct {
+	Bag WebhostGochanBag
+	Out QuicSingleResult
+}
+
+type QuicGochanRunnerOut struct {
+	Out      <-chan QuicGochanOut
+	Progress <-chan string
+}
+
+func QuicGochanRunner(ctx context.Context) QuicGochanRunnerOut {
+	cfg := config.Get().Checkers.Quic
+	out, progress := webhostFarmRunner(webhostFarmRunnerOpt[QuicGochanOut]{
+		Ctx:     ctx,
+		Targets: cfg.Targets,
+		Name:    "quic",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) QuicGochanOut {
+			return QuicGochanOut{
+				Bag: bag,
+				Out: QuicSingle(QuicSingleOpt{Ctx: in.Ctx, Ip: in.Ip, Port: in.Port, Sni: in.Sni, Host: in.Host}),
+			}
+		},
+	})
+	return QuicGochanRunnerOut{Out: out, Progress: progress}
+}
@@ -0,0 +1,173 @@
+// Finds out which SNIs are let through to a "suspicious" destination ip on networks running an SNI whitelist
+// (e.g. where tcp 16-20 is applied to everything except whitelisted domains). Port of tcp-16-20_dwc.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type SniWhitelistOpt struct {
+	Ctx   context.Context
+	DstIp netip.Addr
+	Sni   string
+}
+
+type SniWhitelistResult struct {
+	Sni         string
+	RxBytes     int64 // body bytes
+	Whitelisted bool
+	Err         error
+}
+
+var (
+	ErrSniWhitelistNoDstIp = errors.New("sniwhitelist: invalid or empty destination ip")
+	ErrSniWhitelistNoInput = errors.New("sniwhitelist: empty input list")
+)
+
+// Handshakes with dst ip using the specified sni and downloads a range of bytes.
+// The sni is whitelisted if the whole range has been received.
+func SniWhitelistSingle(opt SniWhitelistOpt) SniWhitelistResult {
+	cfg := config.Get().Checkers.SniWhitelist
+	res := SniWhitelistResult{Sni: opt.Sni}
+
+	ctx, cancel := context.WithTimeout(opt.Ctx, cfg.Timeout)
+	defer cancel()
+
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(inetutil.TlsConnOpt{
+		Ctx:            ctx,
+		Ip:             opt.DstIp,
+		Port:           cfg.Port,
+		Sni:            opt.Sni,
+		TcpConnTimeout: cfg.TcpConnTimeout,
+	})
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer tlsConn.Close()
+
+	req, err := http.NewRequest("GET", "https://"+opt.Sni+cfg.Path, http.NoBody)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	req.Close = true
+	inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", cfg.RangeTo))
+
+	if _, err := inetutil.TlsWriteHttpRequest(ctx, tlsConn, req); err != nil {
+		res.Err = err
+		return res
+	}
+
+	resp, err := inetutil.TlsReadHttpResponse(ctx, tlsConn, bufio.NewReader(tlsConn))
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	// a timeout here is expected for non-whitelisted snis; the received bytes are enough for the verdict.
+	// the body is not closed: it would be drained without a deadline (the connection is closed anyway)
+	res.RxBytes, _ = inetutil.TlsReadHttpBody(ctx, tlsConn, resp.Body)
+	res.Whitelisted = res.RxBytes >= int64(cfg.RangeTo)
+
+	log.Println("sniwhitelist; sni:", opt.Sni, "rx bytes:", res.RxBytes, "whitelisted:", res.Whitelisted)
+	return res
+}
+
+// Scans the whole input list and saves whitelisted snis to the output file.
+func SniWhitelist(ctx context.Context) ([]SniWhitelistResult, error) {
+	gch, err := SniWhitelistGochan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []SniWhitelistResult{}
+	whitelisted := []string{}
+	for x := range gch {
+		items = append(items, x)
+		if x.Whitelisted {
+			whitelisted = append(whitelisted, x.Sni)
+		}
+	}
+
+	slices.Sort(whitelisted)
+	if err := sniWhitelistSave(whitelisted); err != nil {
+		log.Println("sniwhitelist/save", err)
+		return items, err
+	}
+
+	return items, nil
+}
+
+// Reads the input list of domains (one per line).
+func sniWhitelistInput() ([]string, error) {
+	cfg := config.Get().Checkers.SniWhitelist
+	p, err := sniWhitelistPath(cfg.InPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	items := []string{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if x := strings.TrimSpace(sc.Text()); x != "" && !strings.HasPrefix(x, "#") {
+			items = append(items, x)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrSniWhitelistNoInput
+	}
+
+	return items, nil
+}
+
+// Saves whitelisted snis to the output file (one per line).
+func sniWhitelistSave(snis []string) error {
+	cfg := config.Get().Checkers.SniWhitelist
+	p, err := sniWhitelistPath(cfg.OutPath)
+	if err != nil {
+		return err
+	}
+
+	out := strings.Join(snis, "\n")
+	if len(snis) > 0 {
+		out += "\n"
+	}
+	return os.WriteFile(p, []byte(out), 0644)
+}
+
+func sniWhitelistPath(p string) (string, error) {
+	if path.IsAbs(p) {
+		return path.Clean(p), nil
+	}
+
+	binFolder, err := config.BinFolder()
+	if err != nil {
+		return "", err
+	}
+	return path.Clean(path.Join(binFolder, p)), nil
+}
@@ -0,0 +1,38 @@
+package checkers
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/gochan"
+)
+
+func SniWhitelistGochan(ctx context.Context) (<-chan SniWhitelistResult, error) {
+	cfg := config.Get().Checkers.SniWhitelist
+	dstIp, err := netip.ParseAddr(cfg.DstIp)
+	if err != nil || !dstIp.Is4() {
+		return nil, ErrSniWhitelistNoDstIp
+	}
+
+	snis, err := sniWhitelistInput()
+	if err != nil {
+		return nil, err
+	}
+
+	in := make(chan SniWhitelistOpt)
+	out := gochan.Start(gochan.GochanOpt[SniWhitelistOpt, SniWhitelistResult]{
+		Ctx:      ctx,
+		Workers:  cfg.Workers,
+		Input:    in,
+		Executor: SniWhitelistSingle,
+	})
+
+	items := []SniWhitelistOpt{}
+	for _, sni := range snis {
+		items = append(items, SniWhitelistOpt{Ctx: ctx, DstIp: dstIp, Sni: sni})
+	}
+
+	gochan.Push(ctx, in, items)
+	return out, nil
+}
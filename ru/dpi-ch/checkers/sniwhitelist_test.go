@@ -0,0 +1,48 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// The local server freezes the download for all snis except the whitelisted one (like a censor does).
+func TestSniWhitelistSingle(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.SniWhitelist
+	cfg.Timeout = 500 * time.Millisecond
+	content := bytes.Repeat([]byte("."), 4*cfg.RangeTo)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.TLS.ServerName {
+		case "whitelisted.example.com":
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		case "ignores-range.example.com":
+			w.Write(content)
+		default:
+			w.Header().Set("Content-Length", "65536")
+			w.Write(content[:16384])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+	cfg.Port = int(addr.Port())
+
+	cases := map[string]bool{"whitelisted.example.com": true, "ignores-range.example.com": true, "frozen.example.com": false}
+	for sni, want := range cases {
+		res := SniWhitelistSingle(SniWhitelistOpt{Ctx: context.Background(), DstIp: addr.Addr(), Sni: sni})
+		if res.Err != nil || res.Whitelisted != want {
+			t.Errorf("%s: got whitelisted %v (%d rx bytes), %v; want %v", sni, res.Whitelisted, res.RxBytes, res.Err, want)
+		}
+	}
+}
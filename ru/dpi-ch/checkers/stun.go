@@ -0,0 +1,244 @@
+// Checks if a censor blocks voice/video calls (webrtc): stun Binding requests are sent to stun/turn servers
+// over udp and tcp; mapped addresses, rtt and loss are recorded, and udp-only blocking is detected.
+// Mapped addresses from the same local udp socket are also used for nat hints (see whoami).
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type StunOpt struct {
+	Ctx    context.Context
+	Target config.TunnelTarget
+}
+
+type StunTransportResult struct {
+	Err      error          // nil if at least one response is received; otherwise the last error
+	Mapped   netip.AddrPort // from the first response
+	Rtt      time.Duration  // average
+	Sent     int
+	Received int
+}
+
+type StunResult struct {
+	Name    string
+	Host    string
+	Port    int
+	Ip      netip.Addr
+	Udp     StunTransportResult
+	Tcp     StunTransportResult
+	Verdict error
+	Err     error // lookup error; requests are not performed
+}
+
+type StunNatResult struct {
+	Local  netip.AddrPort   // local udp socket
+	Mapped []netip.AddrPort // by servers (from the same local socket)
+	Hint   string
+	Err    error
+}
+
+var (
+	ErrStunUdpBlocked   = errors.New("stun: udp blocked (tcp is alive)")
+	ErrStunUnavailable  = errors.New("stun: server unavailable")
+	ErrStunLookup       = errors.New("stun: lookup error")
+	ErrStunNatNoServers = errors.New("stun: no servers answered for nat hints")
+)
+
+const (
+	StunNatNone        = "none"                 // mapped address is the local one
+	StunNatIndependent = "endpoint-independent" // the same mapped address for all servers (cone nat)
+	StunNatDependent   = "endpoint-dependent"   // the same ip, but different ports (symmetric nat)
+	StunNatMultipleIps = "multiple-ips"         // different ips (e.g. cgnat pool or balancing)
+)
+
+// Returns packet loss in percent.
+func (r StunTransportResult) Loss() int {
+	if r.Sent == 0 {
+		return 0
+	}
+	return (r.Sent - r.Received) * 100 / r.Sent
+}
+
+func Stun(opt StunOpt) StunResult {
+	cfg := config.Get().Checkers.Stun
+	res := StunResult{Name: opt.Target.Name, Host: opt.Target.Host, Port: opt.Target.Port}
+
+	ip, err := endpointLookup(opt.Ctx, opt.Target.Host, cfg.Timeout)
+	if err != nil {
+		log.Println("stun/lookup", opt.Target.Host, err)
+		res.Err = ErrStunLookup
+		return res
+	}
+	res.Ip = ip
+	addr := netip.AddrPortFrom(ip, uint16(opt.Target.Port))
+
+	res.Udp = stunUdp(opt.Ctx, addr)
+	res.Tcp = stunTcp(opt.Ctx, addr)
+	res.Verdict = stunVerdict(res.Udp.Err, res.Tcp.Err)
+	log.Println("stun; ip:", ip, "port:", opt.Target.Port, "udp:", res.Udp, "tcp:", res.Tcp)
+	return res
+}
+
+func stunUdp(ctx context.Context, addr netip.AddrPort) StunTransportResult {
+	conn, err := inetutil.GetUdpListener()
+	if err != nil {
+		return StunTransportResult{Err: err}
+	}
+	defer conn.Close()
+
+	return stunRequests(ctx, func(ctx context.Context) (netip.AddrPort, error) {
+		return inetutil.StunBindingUdp(ctx, conn, addr)
+	})
+}
+
+// All requests are sent over one tcp connection.
+func stunTcp(ctx context.Context, addr netip.AddrPort) StunTransportResult {
+	cfg := config.Get().Checkers.Stun
+	conn, err := inetutil.GetTcpConn(inetutil.TcpConnOpt{Ctx: ctx, Ip: addr.Addr(), Port: int(addr.Port()), TcpConnTimeout: cfg.Timeout})
+	if err != nil {
+		return StunTransportResult{Err: err}
+	}
+	defer conn.Close()
+
+	broken := false
+	return stunRequests(ctx, func(ctx context.Context) (netip.AddrPort, error) {
+		if broken {
+			return netip.AddrPort{}, inetutil.ErrTcpConnClosed
+		}
+		mapped, err := inetutil.StunBindingTcp(ctx, conn)
+		broken = err != nil // the stream is out of sync
+		return mapped, err
+	})
+}
+
+// Performs cfg.Count requests with cfg.Interval between them.
+func stunRequests(ctx context.Context, request func(ctx context.Context) (netip.AddrPort, error)) StunTransportResult {
+	cfg := config.Get().Checkers.Stun
+	res := StunTransportResult{}
+	var rttSum time.Duration
+
+	for i := range max(cfg.Count, 1) {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(cfg.Interval):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		innerCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		start := time.Now()
+		mapped, err := request(innerCtx)
+		rtt := time.Since(start)
+		cancel()
+
+		res.Sent++
+		if err != nil {
+			res.Err = err
+			continue
+		}
+		res.Received++
+		rttSum += rtt
+		if !res.Mapped.IsValid() {
+			res.Mapped = mapped
+		}
+	}
+
+	if res.Received > 0 {
+		res.Err = nil
+		res.Rtt = rttSum / time.Duration(res.Received)
+	}
+	return res
+}
+
+func stunVerdict(udpErr, tcpErr error) error {
+	switch {
+	case udpErr == nil:
+		// many servers don't support tcp, so only udp matters here
+		return nil
+	case tcpErr == nil:
+		return ErrStunUdpBlocked
+	default:
+		return ErrStunUnavailable
+	}
+}
+
+// Queries up to cfg.NatServers servers from the same local udp socket and compares the mapped addresses.
+func StunNat(ctx context.Context) StunNatResult {
+	cfg := config.Get().Checkers.Stun
+	res := StunNatResult{}
+
+	conn, err := inetutil.GetUdpListener()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer conn.Close()
+	localPort := conn.LocalAddr().(*net.UDPAddr).AddrPort().Port()
+
+	for _, t := range cfg.Targets {
+		if len(res.Mapped) >= cfg.NatServers || ctx.Err() != nil {
+			break
+		}
+
+		ip, err := endpointLookup(ctx, t.Host, cfg.Timeout)
+		if err != nil {
+			continue
+		}
+		if !res.Local.IsValid() {
+			res.Local = netip.AddrPortFrom(stunLocalIp(ip), localPort)
+		}
+
+		innerCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		mapped, err := inetutil.StunBindingUdp(innerCtx, conn, netip.AddrPortFrom(ip, uint16(t.Port)))
+		cancel()
+		if err != nil {
+			log.Println("stun/nat", t.Host, err)
+			continue
+		}
+		res.Mapped = append(res.Mapped, mapped)
+	}
+
+	if len(res.Mapped) == 0 {
+		res.Err = ErrStunNatNoServers
+		return res
+	}
+	res.Hint = stunNatHint(res.Local, res.Mapped)
+	return res
+}
+
+func stunNatHint(local netip.AddrPort, mapped []netip.AddrPort) string {
+	switch {
+	case slices.ContainsFunc(mapped, func(x netip.AddrPort) bool { return x.Addr() != mapped[0].Addr() }):
+		return StunNatMultipleIps
+	case slices.ContainsFunc(mapped, func(x netip.AddrPort) bool { return x != mapped[0] }):
+		return StunNatDependent
+	case mapped[0] == local:
+		return StunNatNone
+	default:
+		return StunNatIndependent
+	}
+}
+
+// Returns local ip of the route to ip (no packets are sent).
+func stunLocalIp(ip netip.Addr) netip.Addr {
+	conn, err := inetutil.GetUdpConn(inetutil.UdpConnOpt{Ip: ip, Port: 9})
+	if err != nil {
+		return netip.Addr{}
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).AddrPort().Addr().Unmap()
+}
@@ -0,0 +1,27 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/gochan"
+)
+
+func StunGochan(ctx context.Context) <-chan StunResult {
+	cfg := config.Get().Checkers.Stun
+	in := make(chan StunOpt)
+	out := gochan.Start(gochan.GochanOpt[StunOpt, StunResult]{
+		Ctx:      ctx,
+		Workers:  cfg.Workers,
+		Input:    in,
+		Executor: Stun,
+	})
+
+	items := []StunOpt{}
+	for _, t := range cfg.Targets {
+		items = append(items, StunOpt{Ctx: ctx, Target: t})
+	}
+
+	gochan.Push(ctx, in, items)
+	return out
+}
@@ -0,0 +1,182 @@
+// Checks if a censor blocks vpn/proxy protocols by their first flight: the ssh banner, an openvpn (tcp) hard reset,
+// a random high-entropy payload (shadowsocks-like) and a tls hello (reality-like) are sent to the endpoint,
+// and the reaction (reset, blackhole, answer) is compared with a low-entropy control payload to the same port.
+
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"log"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type TunnelOpt struct {
+	Ctx    context.Context
+	Target config.TunnelTarget
+}
+
+type TunnelProbe struct {
+	Protocol string
+	Err      error // nil is answered; otherwise inetutil error (e.g. reset, read timeout, closed)
+	Verdict  error
+}
+
+type TunnelResult struct {
+	Name    string
+	Host    string
+	Port    int
+	Ip      netip.Addr
+	Control error // as Err in TunnelProbe
+	Probes  []TunnelProbe
+	Err     error // lookup error; probes are not performed
+}
+
+var (
+	ErrTunnelReset           = errors.New("tunnel: reset (unlike control)")
+	ErrTunnelBlackholed      = errors.New("tunnel: blackholed (unlike control)")
+	ErrTunnelClosed          = errors.New("tunnel: closed (unlike control)")
+	ErrTunnelUnavailable     = errors.New("tunnel: endpoint unavailable")
+	ErrTunnelLookup          = errors.New("tunnel: lookup error")
+	ErrTunnelUnknownProtocol = errors.New("tunnel: unknown protocol")
+)
+
+const (
+	TunnelSsh     = "ssh"     // ssh client banner
+	TunnelOpenvpn = "openvpn" // openvpn over tcp: P_CONTROL_HARD_RESET_CLIENT_V2
+	TunnelRandom  = "random"  // fully random payload (shadowsocks, vmess and other "looks like nothing" protocols)
+	TunnelReality = "reality" // tls hello with a popular sni (xray reality)
+)
+
+// Resolves the target once; the control payload is sent first, then every protocol (each one in a new connection).
+func Tunnel(opt TunnelOpt) TunnelResult {
+	cfg := config.Get().Checkers.Tunnel
+	res := TunnelResult{Name: opt.Target.Name, Host: opt.Target.Host, Port: opt.Target.Port}
+
+	ip, err := endpointLookup(opt.Ctx, opt.Target.Host, cfg.TcpConnTimeout)
+	if err != nil {
+		log.Println("tunnel/lookup", opt.Target.Host, err)
+		res.Err = ErrTunnelLookup
+		return res
+	}
+	res.Ip = ip
+
+	res.Control = tunnelProbe(opt.Ctx, ip, opt.Target.Port, bytes.Repeat([]byte{'a'}, cfg.PayloadLen))
+	for _, protocol := range cfg.Protocols {
+		probe := TunnelProbe{Protocol: protocol}
+		payload, err := tunnelPayload(protocol)
+		switch {
+		case err != nil:
+			probe.Err, probe.Verdict = err, err
+		case opt.Ctx.Err() != nil:
+			probe.Err, probe.Verdict = ErrWebhostSkip, ErrWebhostSkip
+		default:
+			probe.Err = tunnelProbe(opt.Ctx, ip, opt.Target.Port, payload)
+			probe.Verdict = tunnelVerdict(res.Control, probe.Err)
+		}
+		res.Probes = append(res.Probes, probe)
+	}
+
+	log.Println("tunnel; ip:", ip, "port:", opt.Target.Port, "control:", res.Control, "probes:", res.Probes)
+	return res
+}
+
+// Returns host as is if it is an ip, otherwise resolves it (the first ipv4 is used).
+func endpointLookup(ctx context.Context, host string, timeout time.Duration) (netip.Addr, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return ip, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip4", host)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if len(ips) == 0 {
+		return netip.Addr{}, errors.New("no ipv4 addresses")
+	}
+	return ips[0].Unmap(), nil
+}
+
+// Sends payload as the first flight and waits for the first bytes of the response.
+func tunnelProbe(ctx context.Context, ip netip.Addr, port int, payload []byte) error {
+	cfg := config.Get().Checkers.Tunnel
+	conn, err := inetutil.GetTcpConn(inetutil.TcpConnOpt{Ctx: ctx, Ip: ip, Port: port, TcpConnTimeout: cfg.TcpConnTimeout})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ReadTimeout)
+	defer cancel()
+	_, err = inetutil.TcpWriteRead(ctx, conn, payload)
+	return err
+}
+
+func tunnelPayload(protocol string) ([]byte, error) {
+	cfg := config.Get().Checkers.Tunnel
+	switch protocol {
+	case TunnelSsh:
+		return []byte("SSH-2.0-OpenSSH_9.6\r\n"), nil
+	case TunnelOpenvpn:
+		// over tcp, the packet is prefixed by its length
+		packet := openvpnHardReset()
+		return append(binary.BigEndian.AppendUint16(nil, uint16(len(packet))), packet...), nil
+	case TunnelRandom:
+		payload := make([]byte, cfg.PayloadLen)
+		rand.Read(payload)
+		return payload, nil
+	case TunnelReality:
+		fingerprint := inetutil.Fingerprints[config.Get().InetUtil.Fingerprint]
+		if fingerprint == nil {
+			fingerprint = inetutil.Fingerprints["chrome"]
+		}
+		return inetutil.UTlsHelloRecord(cfg.RealitySni, *fingerprint)
+	}
+
+	log.Println("tunnel; unknown protocol:", protocol)
+	return nil, ErrTunnelUnknownProtocol
+}
+
+// Returns openvpn P_CONTROL_HARD_RESET_CLIENT_V2 packet (without tls-auth): opcode 7 with key id 0,
+// random session id, empty ack array and packet id 0.
+func openvpnHardReset() []byte {
+	packet := make([]byte, 1+8+1+4)
+	packet[0] = 7 << 3
+	rand.Read(packet[1:9])
+	return packet
+}
+
+// The protocol is considered blocked if the endpoint reacts to it differently than to the control payload
+// (and it is not answered); the endpoint itself may legitimately answer, close or keep silent on garbage.
+func tunnelVerdict(controlErr, probeErr error) error {
+	reacted := func(err error) bool {
+		return err == nil || err == inetutil.ErrTcpConnReset || err == inetutil.ErrTcpConnClosed || err == inetutil.ErrTcpReadTimeout
+	}
+
+	switch {
+	case probeErr == nil:
+		return nil
+	case !reacted(controlErr):
+		return ErrTunnelUnavailable
+	case probeErr == controlErr:
+		return nil
+	case probeErr == inetutil.ErrTcpConnReset:
+		return ErrTunnelReset
+	case probeErr == inetutil.ErrTcpReadTimeout, probeErr == inetutil.ErrTcpConnTimeout:
+		return ErrTunnelBlackholed
+	case probeErr == inetutil.ErrTcpConnClosed:
+		return ErrTunnelClosed
+	default:
+		return ErrTunnelUnavailable
+	}
+}
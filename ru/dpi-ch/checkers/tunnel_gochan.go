@@ -0,0 +1,27 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/gochan"
+)
+
+func TunnelGochan(ctx context.Context) <-chan TunnelResult {
+	cfg := config.Get().Checkers.Tunnel
+	in := make(chan TunnelOpt)
+	out := gochan.Start(gochan.GochanOpt[TunnelOpt, TunnelResult]{
+		Ctx:      ctx,
+		Workers:  cfg.Workers,
+		Input:    in,
+		Executor: Tunnel,
+	})
+
+	items := []TunnelOpt{}
+	for _, t := range cfg.Targets {
+		items = append(items, TunnelOpt{Ctx: ctx, Target: t})
+	}
+
+	gochan.Push(ctx, in, items)
+	return out
+}
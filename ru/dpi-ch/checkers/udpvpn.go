@@ -0,0 +1,154 @@
+// Checks if a censor drops udp vpn handshakes by protocol: wireguard, openvpn and amneziawg (junk-prefixed wireguard)
+// first packets are sent to the endpoint, and the reaction (reply or icmp) is compared with a random-payload control.
+
+package checkers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"log"
+	mrand "math/rand/v2"
+	"net/netip"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+type UdpvpnOpt struct {
+	Ctx    context.Context
+	Target config.TunnelTarget
+}
+
+type UdpvpnProbe struct {
+	Protocol string
+	Err      error // nil is replied; otherwise inetutil error (e.g. port unreachable, read timeout)
+	Verdict  error
+}
+
+type UdpvpnResult struct {
+	Name    string
+	Host    string
+	Port    int
+	Ip      netip.Addr
+	Control error // as Err in UdpvpnProbe
+	Probes  []UdpvpnProbe
+	Err     error // lookup error; probes are not performed
+}
+
+var (
+	ErrUdpvpnDropped         = errors.New("udpvpn: dropped (unlike control)")
+	ErrUdpvpnNoReaction      = errors.New("udpvpn: no reaction (like control)")
+	ErrUdpvpnUnavailable     = errors.New("udpvpn: endpoint unavailable")
+	ErrUdpvpnLookup          = errors.New("udpvpn: lookup error")
+	ErrUdpvpnUnknownProtocol = errors.New("udpvpn: unknown protocol")
+)
+
+const (
+	UdpvpnWireguard = "wireguard" // handshake initiation
+	UdpvpnOpenvpn   = "openvpn"   // P_CONTROL_HARD_RESET_CLIENT_V2
+	UdpvpnAmneziawg = "amneziawg" // junk packets, then handshake initiation with junk prefix and custom type
+)
+
+// Resolves the target once; the control payload is sent first, then every protocol (each one from a new udp socket).
+func Udpvpn(opt UdpvpnOpt) UdpvpnResult {
+	cfg := config.Get().Checkers.Udpvpn
+	res := UdpvpnResult{Name: opt.Target.Name, Host: opt.Target.Host, Port: opt.Target.Port}
+
+	ip, err := endpointLookup(opt.Ctx, opt.Target.Host, cfg.ReplyTimeout)
+	if err != nil {
+		log.Println("udpvpn/lookup", opt.Target.Host, err)
+		res.Err = ErrUdpvpnLookup
+		return res
+	}
+	res.Ip = ip
+
+	control := make([]byte, cfg.ControlLen)
+	rand.Read(control)
+	res.Control = udpvpnProbe(opt.Ctx, ip, opt.Target.Port, [][]byte{control})
+
+	for _, protocol := range cfg.Protocols {
+		probe := UdpvpnProbe{Protocol: protocol}
+		packets, err := udpvpnPackets(protocol)
+		switch {
+		case err != nil:
+			probe.Err, probe.Verdict = err, err
+		case opt.Ctx.Err() != nil:
+			probe.Err, probe.Verdict = ErrWebhostSkip, ErrWebhostSkip
+		default:
+			probe.Err = udpvpnProbe(opt.Ctx, ip, opt.Target.Port, packets)
+			probe.Verdict = udpvpnVerdict(res.Control, probe.Err)
+		}
+		res.Probes = append(res.Probes, probe)
+	}
+
+	log.Println("udpvpn; ip:", ip, "port:", opt.Target.Port, "control:", res.Control, "probes:", res.Probes)
+	return res
+}
+
+// Sends packets up to cfg.Attempts times (udp is lossy) until any reaction: a reply or an icmp error.
+func udpvpnProbe(ctx context.Context, ip netip.Addr, port int, packets [][]byte) error {
+	cfg := config.Get().Checkers.Udpvpn
+	conn, err := inetutil.GetUdpConn(inetutil.UdpConnOpt{Ip: ip, Port: port})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for range max(cfg.Attempts, 1) {
+		innerCtx, cancel := context.WithTimeout(ctx, cfg.ReplyTimeout)
+		_, err = inetutil.UdpWriteRead(innerCtx, conn, packets...)
+		cancel()
+		if err != inetutil.ErrUdpReadTimeout || ctx.Err() != nil {
+			break
+		}
+	}
+	return err
+}
+
+func udpvpnPackets(protocol string) ([][]byte, error) {
+	cfg := config.Get().Checkers.Udpvpn
+	switch protocol {
+	case UdpvpnWireguard:
+		return [][]byte{wireguardInit(1, 0)}, nil
+	case UdpvpnOpenvpn:
+		return [][]byte{openvpnHardReset()}, nil
+	case UdpvpnAmneziawg:
+		packets := [][]byte{}
+		for range cfg.AwgJc {
+			junk := make([]byte, cfg.AwgJmin+mrand.IntN(max(cfg.AwgJmax-cfg.AwgJmin, 0)+1))
+			rand.Read(junk)
+			packets = append(packets, junk)
+		}
+		return append(packets, wireguardInit(cfg.AwgH1, cfg.AwgS1)), nil
+	}
+
+	log.Println("udpvpn; unknown protocol:", protocol)
+	return nil, ErrUdpvpnUnknownProtocol
+}
+
+// Returns wireguard handshake initiation (148 bytes) with the given message type and random junk prefix;
+// the fields (sender index, ephemeral, encrypted static and timestamp, macs) are random.
+func wireguardInit(msgType uint32, junkLen int) []byte {
+	packet := make([]byte, junkLen+148)
+	rand.Read(packet)
+	binary.LittleEndian.PutUint32(packet[junkLen:], msgType)
+	return packet
+}
+
+// The protocol is considered dropped if the endpoint reacts to the control payload (reply or icmp), but not to it.
+func udpvpnVerdict(controlErr, probeErr error) error {
+	switch {
+	case probeErr == nil, probeErr == inetutil.ErrUdpPortUnreachable:
+		return nil
+	case controlErr != nil && controlErr != inetutil.ErrUdpPortUnreachable && controlErr != inetutil.ErrUdpReadTimeout:
+		return ErrUdpvpnUnavailable
+	case probeErr != inetutil.ErrUdpReadTimeout:
+		return ErrUdpvpnUnavailable
+	case controlErr == inetutil.ErrUdpReadTimeout:
+		return ErrUdpvpnNoReaction
+	default:
+		return ErrUdpvpnDropped
+	}
+}
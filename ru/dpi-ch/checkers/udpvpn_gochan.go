@@ -0,0 +1,27 @@
+package checkers
+
+import (
+	"context"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/gochan"
+)
+
+func UdpvpnGochan(ctx context.Context, targets []config.TunnelTarget) <-chan UdpvpnResult {
+	cfg := config.Get().Checkers.Udpvpn
+	in := make(chan UdpvpnOpt)
+	out := gochan.Start(gochan.GochanOpt[UdpvpnOpt, UdpvpnResult]{
+		Ctx:      ctx,
+		Workers:  cfg.Workers,
+		Input:    in,
+		Executor: Udpvpn,
+	})
+
+	items := []UdpvpnOpt{}
+	for _, t := range targets {
+		items = append(items, UdpvpnOpt{Ctx: ctx, Target: t})
+	}
+
+	gochan.Push(ctx, in, items)
+	return out
+}
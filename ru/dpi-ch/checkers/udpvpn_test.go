@@ -0,0 +1,84 @@
+package checkers
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+// Starts a local udp responder that echoes every datagram, except the ones dropped by drop (like a dpi would do).
+func udpvpnTestResponder(t *testing.T, drop func(b []byte) bool) config.TunnelTarget {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		b := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFromUDP(b)
+			if err != nil {
+				return
+			}
+			if !drop(b[:n]) {
+				conn.WriteToUDP(b[:n], addr)
+			}
+		}
+	}()
+
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return config.TunnelTarget{Name: "local", Host: addr.IP.String(), Port: addr.Port}
+}
+
+func udpvpnTestConfig(t *testing.T) {
+	t.Helper()
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.Udpvpn
+	cfg.ReplyTimeout = 200 * time.Millisecond
+	cfg.Attempts = 1
+	cfg.Protocols = []string{UdpvpnWireguard, UdpvpnOpenvpn, UdpvpnAmneziawg}
+}
+
+func TestUdpvpnDropped(t *testing.T) {
+	udpvpnTestConfig(t)
+
+	// drops wireguard handshake initiation (type 1, 148 bytes) only
+	target := udpvpnTestResponder(t, func(b []byte) bool {
+		return len(b) == 148 && b[0] == 1 && b[1] == 0 && b[2] == 0 && b[3] == 0
+	})
+	res := Udpvpn(UdpvpnOpt{Ctx: context.Background(), Target: target})
+
+	if res.Err != nil || res.Control != nil {
+		t.Fatalf("got err %v, control %v", res.Err, res.Control)
+	}
+	want := map[string]error{UdpvpnWireguard: ErrUdpvpnDropped, UdpvpnOpenvpn: nil, UdpvpnAmneziawg: nil}
+	for _, p := range res.Probes {
+		if p.Verdict != want[p.Protocol] {
+			t.Fatalf("%s: got %v, want %v", p.Protocol, p.Verdict, want[p.Protocol])
+		}
+	}
+}
+
+func TestUdpvpnNoReaction(t *testing.T) {
+	udpvpnTestConfig(t)
+
+	target := udpvpnTestResponder(t, func(b []byte) bool { return true })
+	res := Udpvpn(UdpvpnOpt{Ctx: context.Background(), Target: target})
+
+	if res.Control != inetutil.ErrUdpReadTimeout {
+		t.Fatalf("got control %v", res.Control)
+	}
+	for _, p := range res.Probes {
+		if p.Verdict != ErrUdpvpnNoReaction {
+			t.Fatalf("%s: got %v, want %v", p.Protocol, p.Verdict, ErrUdpvpnNoReaction)
+		}
+	}
+}
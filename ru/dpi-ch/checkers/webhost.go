@@ -11,6 +11,7 @@ import (
 	"log"
 	"net/http"
 	"net/netip"
+	"strings"
 	"time"
 
 	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
@@ -39,17 +40,53 @@ type WebhostTls struct {
 }
 
 type WebhostSingleResult struct {
-	IpInfo   inetlookup.IpInfo
-	Port     int
-	Tls      *WebhostTls
-	Sni      string
-	Host     string
-	Alive    error
-	Tcp1620  error
-	Siberian error
+	IpInfo      inetlookup.IpInfo
+	Port        int
+	Tls         *WebhostTls
+	Sni         string
+	Host        string
+	Alive       error
+	Tcp1620     error
+	Tcp1620Down error
+	L4_25       error
+	Siberian    error
+	// Large (post-quantum) vs classic ClientHello; set if pq-key-share is enabled
+	PqKeyShare error
+	// Set only if the http response to the alive check is received
+	AliveResponse *WebhostAliveResponse
 
 	// Set only if Tcp1620 == nil
 	Throughput WebhostThroughput
+	// Set only if Tcp1620Down == nil
+	ThroughputDown WebhostThroughput
+	// Set only if throughput-series is enabled and tcp 16-20 is not skipped
+	ThroughputSeries *WebhostThroughputSeries
+	// Set only if Tcp1620 is detected and tcp1620-bisect is enabled;
+	// the largest request (in bytes) that got through before the freeze; 0 is unknown.
+	Tcp1620Cutoff int64
+	// Set only if sni-matrix is enabled and the host is reachable by tcp
+	SniMatrix []WebhostSniMatrixItem
+}
+
+type WebhostAliveResponse struct {
+	Status   int
+	Location string
+	Body     string // prefix (up to alive-body-bytes)
+}
+
+// The alive check response matches a blockpage signature (i.e. an isp stub page or redirect instead of the real response).
+type WebhostBlockpageError struct {
+	Isp string
+}
+
+func (e *WebhostBlockpageError) Error() string {
+	return "http: blocked by stub page: " + e.Isp
+}
+
+type WebhostSniMatrixItem struct {
+	Kind string // original, empty, random or fallback
+	Sni  string
+	Err  error // handshake outcome
 }
 
 type WebhostThroughput struct {
@@ -59,15 +96,38 @@ type WebhostThroughput struct {
 	RxElapsed time.Duration
 }
 
+type WebhostThroughputSeries struct {
+	Step     time.Duration
+	Samples  []int64 // cumulative rx bytes at the end of each step
+	Complete bool    // all throughput-series-n-bytes are received
+	Err      error   // of the transfer; a read timeout is expected if the host is frozen or throttled
+	Verdict  error   // nil, ErrWebhostSeriesFrozen or ErrWebhostSeriesThrottled (or Err if the transfer failed)
+	// Set only if Verdict is frozen or throttled
+	PlateauStart time.Duration
+	PlateauKbps  float64
+}
+
 var (
 	ErrWebhostBlockedBySni = errors.New("tls: blocked by sni")
 	ErrWebhostInternal     = errors.New("check: internal error")
 	ErrWebhostSkip         = errors.New("check: skip")
+	ErrWebhostNotEnoughRx  = errors.New("check: not enough data received from host")
+	ErrWebhostPqBlocked    = errors.New("tls: large (post-quantum) ClientHello is blocked")
+
+	ErrWebhostSeriesFrozen    = errors.New("throughput: frozen")
+	ErrWebhostSeriesThrottled = errors.New("throughput: throttled")
 )
 
 const RANDOM_HOSTNAME_ALPHABET = "abcdefghijklmnopqrstuvwxyz0123456789"
 const RANDOM_HOSTNAME_LEN = 12
 
+const (
+	SniMatrixOriginal = "original"
+	SniMatrixEmpty    = "empty"
+	SniMatrixRandom   = "random"
+	SniMatrixFallback = "fallback"
+)
+
 func WebhostSingle(opt WebhostSingleOpt) WebhostSingleResult {
 	if opt.RandomHostname {
 		rndHostname, _ := randomHostname()
@@ -95,9 +155,20 @@ func WebhostSingle(opt WebhostSingleOpt) WebhostSingleResult {
 	}
 
 	tlsConn, err := webhostHandshakesCheck(opt, tlsConnOpt)
+	if cfg.SniMatrix && err != inetutil.ErrTcpConnTimeout && opt.Ctx.Err() == nil {
+		res.SniMatrix = webhostSniMatrix(opt, tlsConnOpt)
+	}
+	// runs regardless of the handshake result: the large hello itself may be the reason of the failure
+	if cfg.PqKeyShare && err != inetutil.ErrTcpConnTimeout && opt.Ctx.Err() == nil {
+		res.PqKeyShare = webhostPqKeyShareCheck(tlsConnOpt)
+	} else {
+		res.PqKeyShare = ErrWebhostSkip
+	}
 	if err != nil {
 		res.Alive = err
 		res.Tcp1620 = ErrWebhostSkip
+		res.Tcp1620Down = ErrWebhostSkip
+		res.L4_25 = ErrWebhostSkip
 		res.Siberian = ErrWebhostSkip
 		return res
 	}
@@ -113,15 +184,19 @@ func WebhostSingle(opt WebhostSingleOpt) WebhostSingleResult {
 
 	if opt.Ctx.Err() != nil {
 		res.Tcp1620 = ErrWebhostSkip
+		res.Tcp1620Down = ErrWebhostSkip
+		res.L4_25 = ErrWebhostSkip
 		res.Siberian = ErrWebhostSkip
 		return res
 	}
 
 	// The order of the checks is important.
 
-	res.Alive = webhostAliveCheck(opt, tlsConn)
+	res.AliveResponse, res.Alive = webhostAliveCheck(opt, tlsConn)
 	if res.Alive != nil && res.Alive != inetutil.ErrHttpMalformedResponse {
 		res.Tcp1620 = ErrWebhostSkip
+		res.Tcp1620Down = ErrWebhostSkip
+		res.L4_25 = ErrWebhostSkip
 		res.Siberian = ErrWebhostSkip
 		return res
 	}
@@ -129,11 +204,37 @@ func WebhostSingle(opt WebhostSingleOpt) WebhostSingleResult {
 	if opt.Tcp1620skip || opt.Ctx.Err() != nil {
 		res.Tcp1620 = ErrWebhostSkip
 	} else {
-		thp, err := webhostTcp1620check(opt, tlsConnOpt)
+		thp, err := webhostTcp1620check(opt, tlsConnOpt, cfg.Tcp1620nBytes)
 		if err != nil {
 			res.Tcp1620 = err
 		}
 		res.Throughput = thp
+
+		if cfg.Tcp1620bisect && webhostTcp1620detected(res.Tcp1620) {
+			res.Tcp1620Cutoff = webhostTcp1620bisect(opt, tlsConnOpt)
+		}
+	}
+
+	if opt.Tcp1620skip || opt.Ctx.Err() != nil {
+		res.Tcp1620Down = ErrWebhostSkip
+	} else {
+		thp, err := webhostTcp1620DownCheck(opt, tlsConnOpt)
+		if err != nil {
+			res.Tcp1620Down = err
+		}
+		res.ThroughputDown = thp
+	}
+
+	if cfg.ThroughputSeries && !opt.Tcp1620skip && opt.Ctx.Err() == nil {
+		series := webhostThroughputSeries(opt, tlsConnOpt)
+		res.ThroughputSeries = &series
+	}
+
+	// l4-25 is the same restriction as tcp 16-20 (just triggered by packets count), so it is skipped with it
+	if opt.Tcp1620skip || opt.Ctx.Err() != nil {
+		res.L4_25 = ErrWebhostSkip
+	} else {
+		res.L4_25 = L4_25(L4_25Opt{Ctx: opt.Ctx, TlsConnOpt: tlsConnOpt, Host: opt.Host})
 	}
 
 	if opt.SiberianSkip || opt.Ctx.Err() != nil {
@@ -147,9 +248,8 @@ func WebhostSingle(opt WebhostSingleOpt) WebhostSingleResult {
 
 func webhostHandshakesCheck(webhostOpt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) (*tls.UConn, error) {
 	tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
-	if inetutil.IsInetutilErr(err) && !webhostOpt.RandomHostname && tlsConnOpt.Sni != "" {
-		// TODO: We can determine this in 1 pass using Org by IP + move that into config.
-		snis := []string{"cf.com", "google.com"}
+	if webhostSniFallbackNeeded(webhostOpt, tlsConnOpt, err) {
+		snis := webhostSniFallbacks(inetlookup.Default().IpInfo(tlsConnOpt.Ip).Org)
 		for _, s := range snis {
 			tlsConnOpt.Sni = s
 			tlsConn, err = inetutil.GetHandshakedUTlsConn(tlsConnOpt)
@@ -163,35 +263,133 @@ func webhostHandshakesCheck(webhostOpt WebhostSingleOpt, tlsConnOpt inetutil.Tls
 	return tlsConn, err
 }
 
-func webhostAliveCheck(opt WebhostSingleOpt, tlsConn *tls.UConn) error {
+// Fallback snis are tried only if the handshake with the original sni failed on the network level.
+func webhostSniFallbackNeeded(webhostOpt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt, err error) bool {
+	return inetutil.IsInetutilErr(err) && !webhostOpt.RandomHostname && tlsConnOpt.Sni != ""
+}
+
+// Handshakes with the same ip using the original, empty, random and fallback ("innocent") snis.
+func webhostSniMatrix(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) []WebhostSniMatrixItem {
+	rndHostname, _ := randomHostname()
+	items := []WebhostSniMatrixItem{
+		{Kind: SniMatrixOriginal, Sni: opt.Sni},
+		{Kind: SniMatrixEmpty},
+		{Kind: SniMatrixRandom, Sni: rndHostname},
+	}
+	for _, s := range webhostSniFallbacks(inetlookup.Default().IpInfo(opt.Ip).Org) {
+		items = append(items, WebhostSniMatrixItem{Kind: SniMatrixFallback, Sni: s})
+	}
+
+	for i := range items {
+		if opt.Ctx.Err() != nil {
+			items[i].Err = ErrWebhostSkip
+			continue
+		}
+
+		tlsConnOpt.Sni = items[i].Sni
+		tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
+		if err == nil {
+			tlsConn.Close()
+		}
+		items[i].Err = err
+	}
+
+	log.Println("webhost; ip:", opt.Ip, "sni matrix:", items)
+	return items
+}
+
+// Handshakes with the same ip and sni using a post-quantum (X25519MLKEM768) and a classic-only key share.
+// Returns ErrWebhostPqBlocked if only the large ClientHello (spanning several tcp segments) fails.
+func webhostPqKeyShareCheck(tlsConnOpt inetutil.TlsConnOpt) error {
+	handshake := func(keyShare inetutil.KeyShare) error {
+		tlsConnOpt.KeyShare = keyShare
+		tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
+		if err == nil {
+			tlsConn.Close()
+		}
+		return err
+	}
+
+	pq := handshake(inetutil.KeySharePq)
+	classic := handshake(inetutil.KeyShareClassic)
+	log.Println("webhost; webhostPqKeyShareCheck ip:", tlsConnOpt.Ip, "sni:", tlsConnOpt.Sni, "pq:", pq, "classic:", classic)
+
+	switch {
+	case pq == nil:
+		return nil
+	case classic == nil:
+		return ErrWebhostPqBlocked
+	default:
+		return pq
+	}
+}
+
+// Returns fallback snis for the ip's org (the first matching entry in config).
+func webhostSniFallbacks(org string) []string {
+	cfg := config.Get().Checkers.Webhost
+	org = strings.ToLower(org)
+	for _, x := range cfg.SniFallbacks {
+		if strings.Contains(org, strings.ToLower(x.Org)) {
+			return x.Snis
+		}
+	}
+	return nil
+}
+
+// Sends a ranged GET request and matches the response (status, Location and the body prefix) against blockpages.
+func webhostAliveCheck(opt WebhostSingleOpt, tlsConn *tls.UConn) (*WebhostAliveResponse, error) {
 	defer tlsConn.Close()
 	cfg := config.Get().Checkers.Webhost
 
-	req, err := http.NewRequest("HEAD", "https://"+opt.Host, http.NoBody)
+	req, err := http.NewRequest("GET", "https://"+opt.Host, http.NoBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req.Close = true
 	inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+	if cfg.AliveBodyBytes > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", cfg.AliveBodyBytes-1))
+	}
 
 	writeCtx, cancel := context.WithTimeout(context.Background(), cfg.TcpWriteTimeout)
 	defer cancel()
 	if _, err := inetutil.TlsWriteHttpRequest(writeCtx, tlsConn, req); err != nil {
-		return err
+		return nil, err
 	}
 
 	readCtx, cancel := context.WithTimeout(context.Background(), cfg.TcpReadTimeout)
 	defer cancel()
 	resp, err := inetutil.TlsReadHttpResponse(readCtx, tlsConn, bufio.NewReader(tlsConn))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	resp.Body.Close()
 
-	return nil
+	// the body is not closed: it would be drained without a deadline (the connection is closed anyway);
+	// the stub page may be cut off by a reset, so what has been read is still checked
+	var body strings.Builder
+	if _, err := inetutil.TlsReadHttpBody(readCtx, tlsConn, io.LimitReader(io.TeeReader(resp.Body, &body), int64(cfg.AliveBodyBytes))); err != nil {
+		log.Println("webhost/alive; ip:", opt.Ip, "body:", err)
+	}
+
+	aliveResp := &WebhostAliveResponse{Status: resp.StatusCode, Location: resp.Header.Get("Location"), Body: body.String()}
+	if isp, ok := webhostBlockpage(*aliveResp); ok {
+		log.Println("webhost/alive; ip:", opt.Ip, "blockpage:", isp, "status:", aliveResp.Status, "location:", aliveResp.Location)
+		return aliveResp, &WebhostBlockpageError{Isp: isp}
+	}
+	return aliveResp, nil
 }
 
-func webhostTcp1620check(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) (WebhostThroughput, error) {
+// Returns the isp of the first blockpage signature that matches the response.
+func webhostBlockpage(resp WebhostAliveResponse) (string, bool) {
+	for _, x := range config.Get().Checkers.Webhost.Blockpages {
+		if x.Match(resp.Status, resp.Location, resp.Body) {
+			return x.Isp, true
+		}
+	}
+	return "", false
+}
+
+func webhostTcp1620check(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt, nBytes int) (WebhostThroughput, error) {
 	tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
 	if err != nil {
 		return WebhostThroughput{}, err
@@ -199,7 +397,7 @@ func webhostTcp1620check(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) (
 	defer tlsConn.Close()
 
 	cfg := config.Get().Checkers.Webhost
-	body, _ := randomBytes(cfg.Tcp1620nBytes)
+	body, _ := randomBytes(nBytes)
 
 	// keep-alive increases the chance that we will be able to push enough data into the connection
 	req, err := http.NewRequest("POST", "https://"+opt.Host, bytes.NewReader(body))
@@ -248,6 +446,232 @@ func webhostTcp1620check(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) (
 	}, nil
 }
 
+func webhostTcp1620detected(err error) bool {
+	return err == inetutil.ErrTcpWriteTimeout || err == inetutil.ErrTcpReadTimeout
+}
+
+// Bisects the tcp 16-20 payload size (each probe is a new connection) to find how many bytes get through before the freeze.
+// Stops when the precision is reached or the traffic budget per host is exhausted.
+// Returns the size of the largest request that got through, or 0 if unknown.
+func webhostTcp1620bisect(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) int64 {
+	cfg := config.Get().Checkers.Webhost
+	lo, hi := 0, cfg.Tcp1620nBytes // lo passes (trivially), hi is frozen
+	var cutoff, spent int64
+
+	for hi-lo > max(cfg.Tcp1620bisectPrecision, 1) {
+		mid := lo + (hi-lo)/2
+		if spent+int64(mid) > int64(cfg.Tcp1620bisectBudget) || opt.Ctx.Err() != nil {
+			break
+		}
+		spent += int64(mid)
+
+		thp, err := webhostTcp1620check(opt, tlsConnOpt, mid)
+		switch {
+		case err == nil:
+			lo = mid
+			cutoff = thp.TxBytes
+		case webhostTcp1620detected(err):
+			hi = mid
+		default:
+			// something else is wrong (e.g. conn reset); the result would be unreliable
+			log.Println("webhost; webhostTcp1620bisect ip:", tlsConnOpt.Ip, "n bytes:", mid, "err:", err)
+			return cutoff
+		}
+	}
+
+	log.Println("webhost; webhostTcp1620bisect ip:", tlsConnOpt.Ip, "lo:", lo, "hi:", hi, "cutoff:", cutoff, "spent:", spent)
+	return cutoff
+}
+
+// Download direction of tcp 16-20: pulls at least tcp1620-down-n-bytes from the host.
+// A ranged GET is sent first; if the host returns less, the requests are repeated over the same keep-alive connection.
+func webhostTcp1620DownCheck(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) (WebhostThroughput, error) {
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
+	if err != nil {
+		return WebhostThroughput{}, err
+	}
+	defer tlsConn.Close()
+
+	cfg := config.Get().Checkers.Webhost
+	br := bufio.NewReader(tlsConn)
+	thp := WebhostThroughput{}
+	nBytes := int64(cfg.Tcp1620DownNBytes)
+
+	for range max(cfg.Tcp1620DownMaxRequests, 1) {
+		if opt.Ctx.Err() != nil {
+			return WebhostThroughput{}, ErrWebhostSkip
+		}
+
+		req, err := http.NewRequest("GET", "https://"+opt.Host+cfg.Tcp1620DownPath, http.NoBody)
+		if err != nil {
+			return WebhostThroughput{}, err
+		}
+		req.Close = false
+		inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", nBytes-thp.RxBytes-1))
+
+		writeCtx, cancel := context.WithTimeout(context.Background(), cfg.TcpWriteTimeout)
+		txStart := time.Now()
+		txBytes, err := inetutil.TlsWriteHttpRequest(writeCtx, tlsConn, req)
+		cancel()
+		if err != nil {
+			return WebhostThroughput{}, err
+		}
+		thp.TxBytes += txBytes
+		thp.TxElapsed += time.Since(txStart)
+
+		readCtx, cancel := context.WithTimeout(context.Background(), cfg.TcpReadTimeout)
+		rxStart := time.Now()
+		resp, err := inetutil.TlsReadHttpResponse(readCtx, tlsConn, br)
+		if err != nil {
+			cancel()
+			return WebhostThroughput{}, err
+		}
+		// only the body counts (headers don't); the host may ignore Range, so no more than the remaining bytes are read
+		n, err := inetutil.TlsReadHttpBody(readCtx, tlsConn, io.LimitReader(resp.Body, nBytes-thp.RxBytes))
+		cancel()
+		thp.RxElapsed += time.Since(rxStart)
+		thp.RxBytes += n
+		if err != nil {
+			return WebhostThroughput{}, err
+		}
+
+		// the body isn't closed when there is enough data: the rest would be drained (without any deadline)
+		if thp.RxBytes >= nBytes {
+			return thp, nil
+		}
+		resp.Body.Close()
+		if resp.Close {
+			break
+		}
+	}
+
+	log.Println("webhost; webhostTcp1620DownCheck ip:", tlsConnOpt.Ip, "rx bytes:", thp.RxBytes)
+	return WebhostThroughput{}, ErrWebhostNotEnoughRx
+}
+
+// Downloads throughput-series-n-bytes from the host (like tcp 16-20 in download direction, but longer)
+// and samples the number of received bytes every throughput-series-step.
+func webhostThroughputSeries(opt WebhostSingleOpt, tlsConnOpt inetutil.TlsConnOpt) WebhostThroughputSeries {
+	cfg := config.Get().Checkers.Webhost
+	series := WebhostThroughputSeries{Step: cfg.ThroughputSeriesStep}
+
+	tlsConn, err := inetutil.GetHandshakedUTlsConn(tlsConnOpt)
+	if err != nil {
+		series.Err, series.Verdict = err, err
+		return series
+	}
+	defer tlsConn.Close()
+
+	ctx, cancel := context.WithTimeout(opt.Ctx, cfg.ThroughputSeriesTime)
+	defer cancel()
+	rxSr := &inetutil.SamplingReader{Reader: tlsConn, Interval: cfg.ThroughputSeriesStep}
+	br := bufio.NewReader(rxSr)
+	rxSr.Start()
+
+	for range max(cfg.Tcp1620DownMaxRequests, 1) {
+		req, err := http.NewRequest("GET", "https://"+opt.Host+cfg.Tcp1620DownPath, http.NoBody)
+		if err != nil {
+			series.Err = err
+			break
+		}
+		req.Close = false
+		inetutil.SetHeaders(&req.Header, cfg.HttpStaticHeaders)
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", int64(cfg.ThroughputSeriesNBytes)-rxSr.Bytes-1))
+
+		if _, err := inetutil.TlsWriteHttpRequest(ctx, tlsConn, req); err != nil {
+			series.Err = err
+			break
+		}
+		resp, err := inetutil.TlsReadHttpResponse(ctx, tlsConn, br)
+		if err != nil {
+			series.Err = err
+			break
+		}
+		// the body isn't closed on error: it would be drained (without any deadline)
+		if _, err := inetutil.TlsReadHttpBody(ctx, tlsConn, resp.Body); err != nil {
+			series.Err = err
+			break
+		}
+		resp.Body.Close()
+
+		if rxSr.Bytes >= int64(cfg.ThroughputSeriesNBytes) {
+			series.Complete = true
+			break
+		}
+		if resp.Close {
+			break
+		}
+	}
+
+	rxSr.Flush(time.Now())
+	series.Samples = rxSr.Samples
+	webhostSeriesVerdict(&series)
+	log.Println("webhost; webhostThroughputSeries ip:", tlsConnOpt.Ip, "rx bytes:", rxSr.Bytes, "err:", series.Err, "verdict:", series.Verdict)
+	return series
+}
+
+// Sets the verdict, the point where the plateau begins and its rate.
+// Frozen: the transfer is incomplete and ends with a silence that is longer than any pause before it
+// (a slow throttling also looks like pauses, since the data arrives in whole tls records).
+// Throttled: the rate after a change point is at least throughput-series-ratio times lower than before it.
+func webhostSeriesVerdict(series *WebhostThroughputSeries) {
+	const minSteps = 3 // on each side of the change point
+	cfg := config.Get().Checkers.Webhost
+	samples := series.Samples
+	n := len(samples)
+
+	if series.Err != nil && series.Err != inetutil.ErrTcpReadTimeout {
+		series.Verdict = series.Err
+		return
+	}
+
+	// the freeze: trailing silence
+	last := n - 1 // the last step with data
+	for last >= 0 && samples[last] == webhostSeriesAt(samples, last-1) {
+		last--
+	}
+	var pause, maxPause int
+	for i := range last {
+		if samples[i] == webhostSeriesAt(samples, i-1) {
+			pause++
+			maxPause = max(maxPause, pause)
+		} else {
+			pause = 0
+		}
+	}
+	if silence := n - 1 - last; !series.Complete && silence >= minSteps && silence > 2*maxPause {
+		series.Verdict = ErrWebhostSeriesFrozen
+		series.PlateauStart = time.Duration(last+1) * series.Step
+		return
+	}
+
+	// the throttling: the change point with the largest rate ratio
+	bestK, bestRatio := 0, 0.0
+	for k := minSteps; k <= n-minSteps; k++ {
+		before := float64(samples[k-1]) / float64(k)
+		after := float64(samples[n-1]-samples[k-1]) / float64(n-k)
+		if after > 0 && before/after > bestRatio {
+			bestK, bestRatio = k, before/after
+		}
+	}
+	if bestK > 0 && bestRatio >= cfg.ThroughputSeriesRatio {
+		const bytesToKilobits = 8.0 / 1_000
+		after := float64(samples[n-1]-samples[bestK-1]) / float64(n-bestK)
+		series.Verdict = ErrWebhostSeriesThrottled
+		series.PlateauStart = time.Duration(bestK) * series.Step
+		series.PlateauKbps = after / series.Step.Seconds() * bytesToKilobits
+	}
+}
+
+// Returns samples[i] or 0 before the first sample.
+func webhostSeriesAt(samples []int64, i int) int64 {
+	if i < 0 {
+		return 0
+	}
+	return samples[i]
+}
+
 func webhostSiberianCheck(tlsConnOpt inetutil.TlsConnOpt) error {
 	cfg := config.Get().Checkers.Webhost
 	fingerprint := inetutil.Fingerprints[cfg.SiberianFingerprint]
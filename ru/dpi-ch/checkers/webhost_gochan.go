@@ -64,10 +64,33 @@ type WebhostGochanRunnerOut struct {
 }
 
 func WebhostGochanRunner(opt WebhostGochanRunnerOpt) WebhostGochanRunnerOut {
+	cfg := config.Get().Checkers.Webhost
+	out, progress := webhostFarmRunner(webhostFarmRunnerOpt[WebhostGochanOut[WebhostGochanBag]]{
+		Ctx:     opt.Ctx,
+		Targets: opt.Targets,
+		Name:    "webhost",
+		Workers: cfg.Workers,
+		Executor: func(bag WebhostGochanBag, in WebhostSingleOpt) WebhostGochanOut[WebhostGochanBag] {
+			return WebhostGochanOut[WebhostGochanBag]{Bag: bag, Out: WebhostSingle(in)}
+		},
+	})
+	return WebhostGochanRunnerOut{Out: out, Progress: progress}
+}
+
+type webhostFarmRunnerOpt[Out any] struct {
+	Ctx      context.Context
+	Targets  []config.WebhostTarget
+	Name     string // checker name for progress messages
+	Workers  int
+	Executor func(WebhostGochanBag, WebhostSingleOpt) Out
+}
+
+// Subnetfilter => webhostfarm => executor pipeline; shared by checkers with webhost-style targets.
+func webhostFarmRunner[Out any](opt webhostFarmRunnerOpt[Out]) (<-chan Out, <-chan string) {
 	var wg sync.WaitGroup
 	var optCtxCancel context.CancelFunc
 	progressCh := make(chan string, 16)
-	webhostSendProgress(progressCh, "webhost checker => initialization...")
+	webhostSendProgress(progressCh, opt.Name+" checker => initialization...")
 
 	cfg := config.Get().Checkers.Webhost
 	if cfg.FarmTimeout > 0 {
@@ -91,7 +114,7 @@ func WebhostGochanRunner(opt WebhostGochanRunnerOpt) WebhostGochanRunnerOut {
 				optCtxCancel()
 			}
 		}()
-		return WebhostGochanRunnerOut{Progress: progressCh}
+		return nil, progressCh
 	}
 	gochan.Push(opt.Ctx, sfGochanIn, sfItems)
 
@@ -118,24 +141,28 @@ func WebhostGochanRunner(opt WebhostGochanRunnerOpt) WebhostGochanRunnerOut {
 		}
 	})
 
-	webhostGochanIn := make(chan WebhostGochanIn[WebhostGochanBag])
-	webhostGochan := WebhostGochan(WebhostGochanOpt[WebhostGochanBag]{
-		Ctx: opt.Ctx,
-		In:  webhostGochanIn,
+	execGochanIn := make(chan WebhostGochanIn[WebhostGochanBag])
+	execGochan := gochan.Start(gochan.GochanOpt[WebhostGochanIn[WebhostGochanBag], Out]{
+		Ctx:     opt.Ctx,
+		Workers: opt.Workers,
+		Input:   execGochanIn,
+		Executor: func(in WebhostGochanIn[WebhostGochanBag]) Out {
+			return opt.Executor(in.Bag, in.In)
+		},
 		Post: func() {
 			if optCtxCancel != nil {
 				optCtxCancel()
 			}
 		},
 	})
-	webhostSendProgress(progressCh, "webhost checker => initialized")
+	webhostSendProgress(progressCh, opt.Name+" checker => initialized")
 
 	go func() {
 		defer close(progressCh)
-		defer close(webhostGochanIn)
+		defer close(execGochanIn)
 		defer func() {
 			if opt.Ctx != nil && errors.Is(opt.Ctx.Err(), context.DeadlineExceeded) {
-				webhostSendProgress(progressCh, "webhost checker => farming timeout exceeded; stopping...")
+				webhostSendProgress(progressCh, opt.Name+" checker => farming timeout exceeded; stopping...")
 			}
 		}()
 
@@ -160,7 +187,7 @@ func WebhostGochanRunner(opt WebhostGochanRunnerOpt) WebhostGochanRunnerOut {
 				select {
 				case <-opt.Ctx.Done():
 					return
-				case webhostGochanIn <- in:
+				case execGochanIn <- in:
 				}
 			}
 		}
@@ -168,7 +195,7 @@ func WebhostGochanRunner(opt WebhostGochanRunnerOpt) WebhostGochanRunnerOut {
 		wg.Wait()
 	}()
 
-	return WebhostGochanRunnerOut{Out: webhostGochan, Progress: progressCh}
+	return execGochan, progressCh
 }
 
 func webhostSendProgress(ch chan<- string, p string) {
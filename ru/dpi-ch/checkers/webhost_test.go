@@ -0,0 +1,289 @@
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+)
+
+// Returns cumulative samples for per-step rates.
+func webhostTestSamples(rates ...int64) []int64 {
+	samples := make([]int64, len(rates))
+	var sum int64
+	for i, x := range rates {
+		sum += x
+		samples[i] = sum
+	}
+	return samples
+}
+
+func TestWebhostSeriesVerdict(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	const step = 100 * time.Millisecond
+
+	// 128 kbps throttling after a fast start: the data arrives as whole tls records (16 KB each 10 steps)
+	throttled := []int64{16384, 16384, 16384, 16384, 16384}
+	for i := range 30 {
+		var rate int64
+		if i%10 == 9 {
+			rate = 16384
+		}
+		throttled = append(throttled, rate)
+	}
+
+	cases := map[string]struct {
+		series    WebhostThroughputSeries
+		want      error
+		wantStart time.Duration
+		wantKbps  float64
+	}{
+		"clean": {
+			series: WebhostThroughputSeries{Samples: webhostTestSamples(100_000, 120_000, 90_000, 110_000, 100_000, 100_000, 105_000), Complete: true},
+			want:   nil,
+		},
+		"frozen": {
+			series:    WebhostThroughputSeries{Samples: webhostTestSamples(8192, 8192, 0, 0, 0, 0, 0, 0, 0, 0), Err: inetutil.ErrTcpReadTimeout},
+			want:      ErrWebhostSeriesFrozen,
+			wantStart: 2 * step,
+		},
+		"throttled": {
+			series:    WebhostThroughputSeries{Samples: webhostTestSamples(throttled...), Err: inetutil.ErrTcpReadTimeout},
+			want:      ErrWebhostSeriesThrottled,
+			wantStart: 5 * step,
+			wantKbps:  131,
+		},
+		"reset": {
+			series: WebhostThroughputSeries{Samples: webhostTestSamples(8192), Err: inetutil.ErrTcpConnReset},
+			want:   inetutil.ErrTcpConnReset,
+		},
+	}
+
+	for name, c := range cases {
+		c.series.Step = step
+		webhostSeriesVerdict(&c.series)
+		if c.series.Verdict != c.want || c.series.PlateauStart != c.wantStart || int(c.series.PlateauKbps) != int(c.wantKbps) {
+			t.Errorf("%s: got %v at %s (%.1f kbps), want %v at %s (%.1f kbps)", name,
+				c.series.Verdict, c.series.PlateauStart, c.series.PlateauKbps, c.want, c.wantStart, c.wantKbps)
+		}
+	}
+}
+
+func TestWebhostBlockpage(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		resp WebhostAliveResponse
+		want string
+	}{
+		{WebhostAliveResponse{Status: 200, Body: "<html>hello</html>"}, ""},
+		{WebhostAliveResponse{Status: 302, Location: "https://example.com/login"}, ""},
+		{WebhostAliveResponse{Status: 302, Location: "http://warning.rt.ru/?id=17"}, "Rostelecom"},
+		{WebhostAliveResponse{Status: 302, Location: "http://blackhole.beeline.ru/"}, "Beeline"},
+		{WebhostAliveResponse{Status: 200, Body: "<p>Доступ к информационному ресурсу ограничен</p>"}, "Unknown (RKN registry)"},
+		{WebhostAliveResponse{Status: 451}, "Unknown (451)"},
+	}
+	for _, c := range cases {
+		isp, ok := webhostBlockpage(c.resp)
+		if isp != c.want || ok != (c.want != "") {
+			t.Fatalf("%+v: got %q, want %q", c.resp, isp, c.want)
+		}
+	}
+
+	invalid := config.WebhostBlockpage{Isp: "Invalid", Body: "(unclosed"}
+	if err := invalid.Compile(); err == nil {
+		t.Fatal("invalid regex: got no error")
+	}
+}
+
+func TestWebhostSniFallbacks(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	config.Get().Checkers.Webhost.SniFallbacks = []config.WebhostSniFallback{
+		{Org: "cloudflare", Snis: []string{"cf.com"}},
+		{Org: "Google", Snis: []string{"google.com"}},
+		{Org: "goo", Snis: []string{"shadowed.com"}},
+		{Org: "", Snis: []string{"any.com"}},
+	}
+
+	cases := map[string]string{
+		"Cloudflare, Inc.": "cf.com",
+		"GOOGLE":           "google.com", // case-insensitive; the first matching entry wins
+		"Hetzner Online":   "any.com",
+	}
+	for org, want := range cases {
+		if got := webhostSniFallbacks(org); len(got) != 1 || got[0] != want {
+			t.Errorf("%s: got %v, want [%s]", org, got, want)
+		}
+	}
+
+	config.Get().Checkers.Webhost.SniFallbacks = config.Get().Checkers.Webhost.SniFallbacks[:3]
+	if got := webhostSniFallbacks("Hetzner Online"); got != nil {
+		t.Errorf("no catch-all: got %v, want none", got)
+	}
+}
+
+func TestWebhostSniFallbackNeeded(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().(*net.TCPAddr).AddrPort()
+	ln.Close()
+
+	tlsConnOpt := inetutil.TlsConnOpt{Ip: addr.Addr(), Port: int(addr.Port()), Sni: "example.com"}
+	_, err = inetutil.GetHandshakedUTlsConn(tlsConnOpt)
+	if err != inetutil.ErrTcpConnRefused {
+		t.Fatalf("got %v, want %v", err, inetutil.ErrTcpConnRefused)
+	}
+	if !webhostSniFallbackNeeded(WebhostSingleOpt{}, tlsConnOpt, err) {
+		t.Fatal("refused: fallback snis are not tried")
+	}
+	if webhostSniFallbackNeeded(WebhostSingleOpt{RandomHostname: true}, tlsConnOpt, err) {
+		t.Fatal("random hostname: fallback snis are tried")
+	}
+}
+
+func TestWebhostAliveCheck(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Get()
+	stub := config.WebhostBlockpage{Isp: "Test", Body: "^stub"}
+	if err := stub.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Checkers.Webhost.Blockpages = append(cfg.Checkers.Webhost.Blockpages, stub)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "http://warning.rt.ru/", http.StatusFound)
+		case "/stub":
+			w.Write([]byte("stub" + strings.Repeat(".", 1<<20)))
+		default:
+			if r.Header.Get("Range") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	alive := func(path string) (*WebhostAliveResponse, error) {
+		tlsConn, err := inetutil.GetHandshakedUTlsConn(inetutil.TlsConnOpt{Ip: addr.Addr(), Port: int(addr.Port()), Sni: "example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return webhostAliveCheck(WebhostSingleOpt{Ip: addr.Addr(), Host: "example.com" + path}, tlsConn)
+	}
+
+	if resp, err := alive("/"); err != nil || resp.Status != http.StatusOK {
+		t.Fatalf("ok: got %+v, %v", resp, err)
+	}
+	for path, want := range map[string]string{"/redirect": "Rostelecom", "/stub": "Test"} {
+		resp, err := alive(path)
+		blockpage, ok := errors.AsType[*WebhostBlockpageError](err)
+		if !ok || blockpage.Isp != want {
+			t.Fatalf("%s: got %v, want %s", path, err, want)
+		}
+		if len(resp.Body) > cfg.Checkers.Webhost.AliveBodyBytes {
+			t.Fatalf("%s: body prefix is %d bytes", path, len(resp.Body))
+		}
+	}
+}
+
+func TestWebhostTcp1620DownCheck(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.Webhost
+	cfg.TcpReadTimeout = 2 * time.Second
+	nBytes := cfg.Tcp1620DownNBytes
+	content := bytes.Repeat([]byte("."), 4*nBytes)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/range":
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		case "/short":
+			w.Header().Set("Connection", "close")
+			w.Write(content[:nBytes/2])
+		default:
+			// ignores Range and keeps streaming way past the read timeout
+			w.Write(content)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	down := func(path string) (WebhostThroughput, error) {
+		cfg.Tcp1620DownPath = path
+		opt := WebhostSingleOpt{Ctx: context.Background(), Ip: addr.Addr(), Host: "example.com"}
+		return webhostTcp1620DownCheck(opt, inetutil.TlsConnOpt{Ip: addr.Addr(), Port: int(addr.Port()), Sni: "example.com"})
+	}
+
+	for _, path := range []string{"/range", "/norange"} {
+		if thp, err := down(path); err != nil || thp.RxBytes != int64(nBytes) {
+			t.Fatalf("%s: got %d rx bytes, %v; want %d", path, thp.RxBytes, err, nBytes)
+		}
+	}
+	if _, err := down("/short"); err != ErrWebhostNotEnoughRx {
+		t.Fatalf("short: got %v, want %v", err, ErrWebhostNotEnoughRx)
+	}
+}
+
+// The local server never answers requests with a body above the limit (as if the connection was frozen).
+func TestWebhostTcp1620bisect(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Get().Checkers.Webhost
+	cfg.TcpReadTimeout = 100 * time.Millisecond
+	const limit = 20000
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.ContentLength > limit {
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	bisect := func(budget int) int64 {
+		cfg.Tcp1620bisectBudget = budget
+		opt := WebhostSingleOpt{Ctx: context.Background(), Ip: addr.Addr(), Host: "example.com"}
+		return webhostTcp1620bisect(opt, inetutil.TlsConnOpt{Ip: addr.Addr(), Port: int(addr.Port()), Sni: "example.com"})
+	}
+
+	// the cutoff is the whole request, i.e. the body plus a few hundred bytes of headers
+	if cutoff := bisect(1 << 20); cutoff < limit-int64(cfg.Tcp1620bisectPrecision) || cutoff > limit+1024 {
+		t.Fatalf("got cutoff %d, want about %d", cutoff, limit)
+	}
+	// the first probe (half of tcp1620-n-bytes) is frozen, and there is no budget for the next one
+	if cutoff := bisect(cfg.Tcp1620nBytes / 2); cutoff != 0 {
+		t.Fatalf("exhausted budget: got cutoff %d, want 0", cutoff)
+	}
+}
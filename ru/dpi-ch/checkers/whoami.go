@@ -16,6 +16,7 @@ type WhoamiResult struct {
 	Org      string
 	Location string
 	Ttlb     time.Duration
+	Nat      StunNatResult // udp mapped addresses from stun servers (see stun checker)
 }
 
 func Whoami() (WhoamiResult, error) {
@@ -36,6 +37,7 @@ func Whoami() (WhoamiResult, error) {
 
 	il := inetlookup.Default()
 	info := il.IpInfo(ip)
+	nat := StunNat(context.Background())
 
 	return WhoamiResult{
 		Ip:       info.Ip.String(),
@@ -44,5 +46,6 @@ func Whoami() (WhoamiResult, error) {
 		Org:      info.Org,
 		Location: info.CountryIso,
 		Ttlb:     ttlb,
+		Nat:      nat,
 	}, nil
 }
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	_ "embed"
 	"errors"
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime"
 	"time"
 
@@ -25,20 +27,40 @@ type Config struct {
 		} `mapstructure:"cidrwhitelist"`
 
 		Webhost struct {
-			Sections            []WebhostSection  `mapstructure:"sections"`
-			Workers             int               `mapstructure:"workers"`
-			FarmTimeout         time.Duration     `mapstructure:"farm-timeout"`
-			TcpConnTimeout      time.Duration     `mapstructure:"tcp-conn-timeout"`
-			TlsHandshakeTimeout time.Duration     `mapstructure:"tls-handshake-timeout"`
-			TcpReadTimeout      time.Duration     `mapstructure:"tcp-read-timeout"`
-			TcpWriteTimeout     time.Duration     `mapstructure:"tcp-write-timeout"`
-			TcpWriteBuf         int               `mapstructure:"tcp-write-buf"`
-			TcpReadBuf          int               `mapstructure:"tcp-read-buf"`
-			Tcp1620nBytes       int               `mapstructure:"tcp1620-n-bytes"`
-			SiberianConnCount   int               `mapstructure:"siberian-conn-count"`
-			SiberianFingerprint string            `mapstructure:"siberian-fingerprint"`
-			TableMaxVisibleRows int               `mapstructure:"table-max-visible-rows"`
-			HttpStaticHeaders   map[string]string `mapstructure:"http-static-headers"`
+			Sections               []WebhostSection     `mapstructure:"sections"`
+			Workers                int                  `mapstructure:"workers"`
+			FarmTimeout            time.Duration        `mapstructure:"farm-timeout"`
+			TcpConnTimeout         time.Duration        `mapstructure:"tcp-conn-timeout"`
+			TlsHandshakeTimeout    time.Duration        `mapstructure:"tls-handshake-timeout"`
+			TcpReadTimeout         time.Duration        `mapstructure:"tcp-read-timeout"`
+			TcpWriteTimeout        time.Duration        `mapstructure:"tcp-write-timeout"`
+			TcpWriteBuf            int                  `mapstructure:"tcp-write-buf"`
+			TcpReadBuf             int                  `mapstructure:"tcp-read-buf"`
+			Tcp1620nBytes          int                  `mapstructure:"tcp1620-n-bytes"`
+			Tcp1620bisect          bool                 `mapstructure:"tcp1620-bisect"`
+			Tcp1620bisectBudget    int                  `mapstructure:"tcp1620-bisect-budget"`
+			Tcp1620bisectPrecision int                  `mapstructure:"tcp1620-bisect-precision"`
+			Tcp1620DownNBytes      int                  `mapstructure:"tcp1620-down-n-bytes"`
+			Tcp1620DownPath        string               `mapstructure:"tcp1620-down-path"`
+			Tcp1620DownMaxRequests int                  `mapstructure:"tcp1620-down-max-requests"`
+			ThroughputSeries       bool                 `mapstructure:"throughput-series"`
+			ThroughputSeriesNBytes int                  `mapstructure:"throughput-series-n-bytes"`
+			ThroughputSeriesTime   time.Duration        `mapstructure:"throughput-series-time"`
+			ThroughputSeriesStep   time.Duration        `mapstructure:"throughput-series-step"`
+			ThroughputSeriesRatio  float64              `mapstructure:"throughput-series-ratio"`
+			SniMatrix              bool                 `mapstructure:"sni-matrix"`
+			PqKeyShare             bool                 `mapstructure:"pq-key-share"`
+			SniFallbacks           []WebhostSniFallback `mapstructure:"sni-fallbacks"`
+			AliveBodyBytes         int                  `mapstructure:"alive-body-bytes"`
+			Blockpages             []WebhostBlockpage   `mapstructure:"blockpages"`
+			L4_25nBytes            int                  `mapstructure:"l4-25-n-bytes"`
+			L4_25chunkSize         int                  `mapstructure:"l4-25-chunk-size"`
+			L4_25chunkDelay        time.Duration        `mapstructure:"l4-25-chunk-delay"`
+			L4_25respTimeout       time.Duration        `mapstructure:"l4-25-resp-timeout"`
+			SiberianConnCount      int                  `mapstructure:"siberian-conn-count"`
+			SiberianFingerprint    string               `mapstructure:"siberian-fingerprint"`
+			TableMaxVisibleRows    int                  `mapstructure:"table-max-visible-rows"`
+			HttpStaticHeaders      map[string]string    `mapstructure:"http-static-headers"`
 		} `mapstructure:"webhost"`
 
 		Dns struct {
@@ -66,6 +88,23 @@ type Config struct {
 					HttpStaticHeaders map[string]string `mapstructure:"http-static-headers"`
 				} `mapstructure:"doh-opt"`
 
+				DotOpt struct {
+					Timeout time.Duration `mapstructure:"timeout"`
+					Workers int           `mapstructure:"workers"`
+					Port    int           `mapstructure:"port"`
+				} `mapstructure:"dot-opt"`
+
+				DoqOpt struct {
+					Timeout time.Duration `mapstructure:"timeout"`
+					Workers int           `mapstructure:"workers"`
+					Port    int           `mapstructure:"port"`
+				} `mapstructure:"doq-opt"`
+
+				Doh3Opt struct {
+					Timeout time.Duration `mapstructure:"timeout"`
+					Workers int           `mapstructure:"workers"`
+				} `mapstructure:"doh3-opt"`
+
 				Targets []struct {
 					Host   string `mapstructure:"host"`
 					Filter string `mapstructure:"filter"`
@@ -78,6 +117,18 @@ type Config struct {
 						Filter string   `mapstructure:"filter"`
 						Hosts  []string `mapstructure:"hosts"`
 					} `mapstructure:"doh"`
+					DoT struct {
+						Filter string   `mapstructure:"filter"`
+						Hosts  []string `mapstructure:"hosts"`
+					} `mapstructure:"dot"`
+					DoQ struct {
+						Filter string   `mapstructure:"filter"`
+						Hosts  []string `mapstructure:"hosts"`
+					} `mapstructure:"doq"`
+					DoH3 struct {
+						Filter string   `mapstructure:"filter"`
+						Hosts  []string `mapstructure:"hosts"`
+					} `mapstructure:"doh3"`
 				} `mapstructure:"providers"`
 			} `mapstructure:"resolve"`
 		} `mapstructure:"dns"`
@@ -85,6 +136,167 @@ type Config struct {
 		Whoami struct {
 			Timeout time.Duration `mapstructure:"timeout"`
 		} `mapstructure:"whoami"`
+
+		SniWhitelist struct {
+			InPath            string            `mapstructure:"in-path"`
+			OutPath           string            `mapstructure:"out-path"`
+			DstIp             string            `mapstructure:"dst-ip"`
+			Port              int               `mapstructure:"port"`
+			Path              string            `mapstructure:"path"`
+			RangeTo           int               `mapstructure:"range-to"`
+			Timeout           time.Duration     `mapstructure:"timeout"`
+			TcpConnTimeout    time.Duration     `mapstructure:"tcp-conn-timeout"`
+			Workers           int               `mapstructure:"workers"`
+			HttpStaticHeaders map[string]string `mapstructure:"http-static-headers"`
+		} `mapstructure:"sniwhitelist"`
+
+		Compression struct {
+			Targets             []string          `mapstructure:"targets"`
+			Encodings           []string          `mapstructure:"encodings"`
+			Workers             int               `mapstructure:"workers"`
+			Timeout             time.Duration     `mapstructure:"timeout"`
+			TcpConnTimeout      time.Duration     `mapstructure:"tcp-conn-timeout"`
+			MinBytes            int               `mapstructure:"min-bytes"`
+			Chunk               int               `mapstructure:"chunk"`
+			TableMaxVisibleRows int               `mapstructure:"table-max-visible-rows"`
+			HttpStaticHeaders   map[string]string `mapstructure:"http-static-headers"`
+		} `mapstructure:"compression"`
+
+		Quic struct {
+			Targets             []WebhostTarget `mapstructure:"targets"`
+			Workers             int             `mapstructure:"workers"`
+			HandshakeTimeout    time.Duration   `mapstructure:"handshake-timeout"`
+			Versions            []int           `mapstructure:"versions"`
+			Alpn                []string        `mapstructure:"alpn"`
+			TableMaxVisibleRows int             `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"quic"`
+
+		HttpHost struct {
+			Targets             []string          `mapstructure:"targets"`
+			Port                int               `mapstructure:"port"`
+			Workers             int               `mapstructure:"workers"`
+			Timeout             time.Duration     `mapstructure:"timeout"`
+			MaxBodyBytes        int               `mapstructure:"max-body-bytes"`
+			StubSignatures      []string          `mapstructure:"stub-signatures"`
+			TableMaxVisibleRows int               `mapstructure:"table-max-visible-rows"`
+			HttpStaticHeaders   map[string]string `mapstructure:"http-static-headers"`
+		} `mapstructure:"httphost"`
+
+		Fingerprint struct {
+			Targets             []WebhostTarget   `mapstructure:"targets"`
+			Workers             int               `mapstructure:"workers"`
+			TcpConnTimeout      time.Duration     `mapstructure:"tcp-conn-timeout"`
+			TlsHandshakeTimeout time.Duration     `mapstructure:"tls-handshake-timeout"`
+			ReadTimeout         time.Duration     `mapstructure:"read-timeout"`
+			ThrottleThreshold   time.Duration     `mapstructure:"throttle-threshold"`
+			TableMaxVisibleRows int               `mapstructure:"table-max-visible-rows"`
+			HttpStaticHeaders   map[string]string `mapstructure:"http-static-headers"`
+		} `mapstructure:"fingerprint"`
+
+		Bypass struct {
+			Targets             []WebhostTarget `mapstructure:"targets"`
+			Workers             int             `mapstructure:"workers"`
+			TcpConnTimeout      time.Duration   `mapstructure:"tcp-conn-timeout"`
+			TlsHandshakeTimeout time.Duration   `mapstructure:"tls-handshake-timeout"`
+			Strategies          []string        `mapstructure:"strategies"`
+			SplitDelay          time.Duration   `mapstructure:"split-delay"`
+			Padding             int             `mapstructure:"padding"`
+			TinySegmentSize     int             `mapstructure:"tiny-segment-size"`
+			TinySegmentDelay    time.Duration   `mapstructure:"tiny-segment-delay"`
+			TableMaxVisibleRows int             `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"bypass"`
+
+		Ech struct {
+			Targets             []WebhostTarget `mapstructure:"targets"`
+			Workers             int             `mapstructure:"workers"`
+			TcpConnTimeout      time.Duration   `mapstructure:"tcp-conn-timeout"`
+			TlsHandshakeTimeout time.Duration   `mapstructure:"tls-handshake-timeout"`
+			EchConfig           string          `mapstructure:"ech-config"`
+			EchConfigHost       string          `mapstructure:"ech-config-host"`
+			Doh                 struct {
+				Host string   `mapstructure:"host"`
+				Ips  []string `mapstructure:"ips"`
+			} `mapstructure:"doh"`
+			TableMaxVisibleRows int `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"ech"`
+
+		Tunnel struct {
+			Targets             []TunnelTarget `mapstructure:"targets"`
+			Protocols           []string       `mapstructure:"protocols"`
+			Workers             int            `mapstructure:"workers"`
+			TcpConnTimeout      time.Duration  `mapstructure:"tcp-conn-timeout"`
+			ReadTimeout         time.Duration  `mapstructure:"read-timeout"`
+			PayloadLen          int            `mapstructure:"payload-len"`
+			RealitySni          string         `mapstructure:"reality-sni"`
+			TableMaxVisibleRows int            `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"tunnel"`
+
+		Udpvpn struct {
+			Sections            []UdpvpnSection `mapstructure:"sections"`
+			Protocols           []string        `mapstructure:"protocols"`
+			Workers             int             `mapstructure:"workers"`
+			ReplyTimeout        time.Duration   `mapstructure:"reply-timeout"`
+			Attempts            int             `mapstructure:"attempts"`
+			ControlLen          int             `mapstructure:"control-len"`
+			AwgJc               int             `mapstructure:"awg-jc"`
+			AwgJmin             int             `mapstructure:"awg-jmin"`
+			AwgJmax             int             `mapstructure:"awg-jmax"`
+			AwgS1               int             `mapstructure:"awg-s1"`
+			AwgH1               uint32          `mapstructure:"awg-h1"`
+			TableMaxVisibleRows int             `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"udpvpn"`
+
+		Stun struct {
+			Targets             []TunnelTarget `mapstructure:"targets"`
+			Workers             int            `mapstructure:"workers"`
+			Count               int            `mapstructure:"count"`
+			Interval            time.Duration  `mapstructure:"interval"`
+			Timeout             time.Duration  `mapstructure:"timeout"`
+			NatServers          int            `mapstructure:"nat-servers"`
+			TableMaxVisibleRows int            `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"stun"`
+
+		Idle struct {
+			Targets             []WebhostTarget   `mapstructure:"targets"`
+			Workers             int               `mapstructure:"workers"`
+			TcpConnTimeout      time.Duration     `mapstructure:"tcp-conn-timeout"`
+			TlsHandshakeTimeout time.Duration     `mapstructure:"tls-handshake-timeout"`
+			ReadTimeout         time.Duration     `mapstructure:"read-timeout"`
+			Intervals           []time.Duration   `mapstructure:"intervals"`
+			Keepalives          []string          `mapstructure:"keepalives"`
+			KeepalivePeriod     time.Duration     `mapstructure:"keepalive-period"`
+			TableMaxVisibleRows int               `mapstructure:"table-max-visible-rows"`
+			HttpStaticHeaders   map[string]string `mapstructure:"http-static-headers"`
+		} `mapstructure:"idle"`
+
+		Portscan struct {
+			Targets             []WebhostTarget `mapstructure:"targets"`
+			Ports               []int           `mapstructure:"ports"`
+			Workers             int             `mapstructure:"workers"`
+			TcpConnTimeout      time.Duration   `mapstructure:"tcp-conn-timeout"`
+			ReadTimeout         time.Duration   `mapstructure:"read-timeout"`
+			TableMaxVisibleRows int             `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"portscan"`
+
+		Dnsinject struct {
+			Ips                 []string        `mapstructure:"ips"`
+			Targets             []WebhostTarget `mapstructure:"targets"`
+			Sensitive           []string        `mapstructure:"sensitive"`
+			Control             []string        `mapstructure:"control"`
+			Port                int             `mapstructure:"port"`
+			Timeout             time.Duration   `mapstructure:"timeout"`
+			Workers             int             `mapstructure:"workers"`
+			TableMaxVisibleRows int             `mapstructure:"table-max-visible-rows"`
+		} `mapstructure:"dnsinject"`
+
+		Diagnose struct {
+			Port int `mapstructure:"port"`
+			Doh  struct {
+				Host string   `mapstructure:"host"`
+				Ips  []string `mapstructure:"ips"`
+			} `mapstructure:"doh"`
+			Host string `mapstructure:"host"` // set by "diagnose <host>" command
+		} `mapstructure:"diagnose"`
 	} `mapstructure:"checkers"`
 
 	All struct {
@@ -181,6 +393,61 @@ type WebhostTarget struct {
 	RandomHostname bool   `mapstructure:"random-hostname"`
 }
 
+type UdpvpnSection struct {
+	Name    string         `mapstructure:"name"`
+	Desc    string         `mapstructure:"desc"`
+	Default bool           `mapstructure:"default"`
+	Targets []TunnelTarget `mapstructure:"targets"`
+}
+
+type TunnelTarget struct {
+	Name string `mapstructure:"name"`
+	Host string `mapstructure:"host"` // hostname or ip
+	Port int    `mapstructure:"port"`
+}
+
+type WebhostSniFallback struct {
+	Org  string   `mapstructure:"org"`
+	Snis []string `mapstructure:"snis"`
+}
+
+// Stub page signature; all non-empty fields must match (regexes are go-style, matched anywhere in the value).
+type WebhostBlockpage struct {
+	Isp      string `mapstructure:"isp"`
+	Status   int    `mapstructure:"status"`
+	Location string `mapstructure:"location"`
+	Body     string `mapstructure:"body"`
+
+	locationRe *regexp.Regexp // compiled on load
+	bodyRe     *regexp.Regexp
+}
+
+// Compiles the regexes; it is done by Load, so only entries added at runtime need it.
+func (x *WebhostBlockpage) Compile() error {
+	var err error
+	if x.Location != "" {
+		if x.locationRe, err = regexp.Compile(x.Location); err != nil {
+			return fmt.Errorf("blockpage %q location: %w", x.Isp, err)
+		}
+	}
+	if x.Body != "" {
+		if x.bodyRe, err = regexp.Compile(x.Body); err != nil {
+			return fmt.Errorf("blockpage %q body: %w", x.Isp, err)
+		}
+	}
+	return nil
+}
+
+// Reports whether the response matches the signature; an empty signature matches nothing.
+func (x *WebhostBlockpage) Match(status int, location, body string) bool {
+	if x.Status == 0 && x.Location == "" && x.Body == "" {
+		return false
+	}
+	return (x.Status == 0 || x.Status == status) &&
+		(x.locationRe == nil || x.locationRe.MatchString(location)) &&
+		(x.bodyRe == nil || x.bodyRe.MatchString(body))
+}
+
 const CfgDefPath = "config.yaml"
 
 var _cfg = &Config{}
@@ -225,6 +492,23 @@ func Load(path string) error {
 		_cfg.Checkers.Webhost.Sections = append(defSec, userSec...)
 	}
 
+	defBlockpages := defTmp.Checkers.Webhost.Blockpages
+	userBlockpages := _cfg.Checkers.Webhost.Blockpages
+	if !reflect.DeepEqual(defBlockpages, userBlockpages) {
+		_cfg.Checkers.Webhost.Blockpages = append(defBlockpages, userBlockpages...)
+	}
+	for i := range _cfg.Checkers.Webhost.Blockpages {
+		if err := _cfg.Checkers.Webhost.Blockpages[i].Compile(); err != nil {
+			return err
+		}
+	}
+
+	defUdpvpnSec := defTmp.Checkers.Udpvpn.Sections
+	userUdpvpnSec := _cfg.Checkers.Udpvpn.Sections
+	if !reflect.DeepEqual(defUdpvpnSec, userUdpvpnSec) {
+		_cfg.Checkers.Udpvpn.Sections = append(defUdpvpnSec, userUdpvpnSec...)
+	}
+
 	// TODO: add config validator
 	_path = path
 	return nil
@@ -286,3 +570,7 @@ func ForceUpdate() {
 func RunAllChecksImmediately() {
 	_cfg.All.Flag = true
 }
+
+func DiagnoseImmediately(host string) {
+	_cfg.Checkers.Diagnose.Host = host
+}
@@ -0,0 +1,50 @@
+package inetutil
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+
+	tls "github.com/refraction-networking/utls"
+)
+
+var ErrTlsEchRejected = errors.New("tls: ech rejected")
+
+// Returned if the server rejected ech (i.e. the outer ClientHello was used);
+// it may contain a new ECHConfigList that the server suggests using for retries.
+type EchRejectionError struct {
+	RetryConfigList []byte
+}
+
+func (e *EchRejectionError) Error() string {
+	return ErrTlsEchRejected.Error()
+}
+
+func (e *EchRejectionError) Is(target error) bool {
+	return target == ErrTlsEchRejected
+}
+
+// Decodes base64 ECHConfigList (as in the "ech" param of HTTPS dns records).
+func ParseEchConfigList(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// Makes sure that spec contains an ech extension (a real one is made of it when ECHConfigList is set).
+func setUTlsEch(spec *tls.ClientHelloSpec) {
+	has := slices.ContainsFunc(spec.Extensions, func(ext tls.TLSExtension) bool {
+		_, ok := ext.(tls.EncryptedClientHelloExtension)
+		return ok
+	})
+	if has {
+		return
+	}
+
+	last := len(spec.Extensions) - 1
+	if last >= 0 {
+		if _, ok := spec.Extensions[last].(tls.PreSharedKeyExtension); ok {
+			spec.Extensions = slices.Insert(spec.Extensions, last, tls.TLSExtension(tls.BoringGREASEECH()))
+			return
+		}
+	}
+	spec.Extensions = append(spec.Extensions, tls.BoringGREASEECH())
+}
@@ -0,0 +1,130 @@
+package inetutil
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// Returns a single ECHConfig (draft-ietf-tls-esni, x25519 + hkdf-sha256 + aes-128-gcm) and its private key.
+func echTestConfig(t *testing.T, id byte, publicName string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c []byte
+	c = append(c, id)
+	c = binary.BigEndian.AppendUint16(c, 0x0020) // kem: dhkem(x25519, hkdf-sha256)
+	pub := key.PublicKey().Bytes()
+	c = binary.BigEndian.AppendUint16(c, uint16(len(pub)))
+	c = append(c, pub...)
+	c = binary.BigEndian.AppendUint16(c, 4)
+	c = binary.BigEndian.AppendUint16(c, 0x0001) // kdf: hkdf-sha256
+	c = binary.BigEndian.AppendUint16(c, 0x0001) // aead: aes-128-gcm
+	c = append(c, 0)                             // max name length
+	c = append(c, byte(len(publicName)))
+	c = append(c, publicName...)
+	c = binary.BigEndian.AppendUint16(c, 0) // extensions
+
+	var out []byte
+	out = binary.BigEndian.AppendUint16(out, 0xfe0d)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(c)))
+	out = append(out, c...)
+	return out, key.Bytes()
+}
+
+func echTestConfigList(configs ...[]byte) []byte {
+	var list []byte
+	for _, c := range configs {
+		list = append(list, c...)
+	}
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(list))), list...)
+}
+
+// Starts a local tls server stand-in with the given ech keys; returns its address.
+func echTestServer(t *testing.T, keys []tls.EncryptedClientHelloKey) netip.AddrPort {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS13, EncryptedClientHelloKeys: keys}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return netip.MustParseAddrPort(srv.Listener.Addr().String())
+}
+
+func echTestHandshake(addr netip.AddrPort, echConfigList []byte) (bool, error) {
+	conn, err := GetHandshakedUTlsConn(TlsConnOpt{
+		Ip:                  addr.Addr(),
+		Port:                int(addr.Port()),
+		Sni:                 "inner.example.com",
+		TlsHandshakeTimeout: 3 * time.Second,
+		EchConfigList:       echConfigList,
+	})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().ECHAccepted, nil
+}
+
+func TestEchAccept(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	echConfig, key := echTestConfig(t, 1, "public.example.com")
+	addr := echTestServer(t, []tls.EncryptedClientHelloKey{{Config: echConfig, PrivateKey: key}})
+
+	accepted, err := echTestHandshake(addr, echTestConfigList(echConfig))
+	if err != nil || !accepted {
+		t.Fatalf("got accepted %v, err %v; want accepted", accepted, err)
+	}
+}
+
+func TestEchRetry(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	echConfig, key := echTestConfig(t, 1, "public.example.com")
+	staleConfig, _ := echTestConfig(t, 2, "public.example.com")
+	addr := echTestServer(t, []tls.EncryptedClientHelloKey{{Config: echConfig, PrivateKey: key, SendAsRetry: true}})
+
+	_, err := echTestHandshake(addr, echTestConfigList(staleConfig))
+	rejection, ok := errors.AsType[*EchRejectionError](err)
+	if !ok || len(rejection.RetryConfigList) == 0 {
+		t.Fatalf("got %v, want %v with retry configs", err, ErrTlsEchRejected)
+	}
+
+	accepted, err := echTestHandshake(addr, rejection.RetryConfigList)
+	if err != nil || !accepted {
+		t.Fatalf("retry: got accepted %v, err %v; want accepted", accepted, err)
+	}
+}
+
+func TestEchReject(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	echConfig, _ := echTestConfig(t, 1, "public.example.com")
+	addr := echTestServer(t, nil) // ech is not supported by server
+
+	_, err := echTestHandshake(addr, echTestConfigList(echConfig))
+	if !errors.Is(err, ErrTlsEchRejected) || !IsInetutilErr(err) {
+		t.Fatalf("got %v, want %v", err, ErrTlsEchRejected)
+	}
+	if rejection, _ := errors.AsType[*EchRejectionError](err); len(rejection.RetryConfigList) != 0 {
+		t.Fatalf("got retry configs, want none")
+	}
+}
@@ -0,0 +1,170 @@
+package inetutil
+
+import (
+	"encoding/binary"
+	"net"
+	"slices"
+	"time"
+
+	tls "github.com/refraction-networking/utls"
+)
+
+// Writes the ClientHello (the whole tls record incl. header) into the tcp connection.
+// It is used for app-level dpi evasion, e.g. splitting the hello into several tcp segments.
+type HelloWriteFunc func(conn *net.TCPConn, hello []byte) error
+
+// Passes the first write (i.e. ClientHello) to HelloWriteFunc; the rest is written as is.
+type helloWriteConn struct {
+	*net.TCPConn
+	write HelloWriteFunc
+	done  bool
+}
+
+func (c *helloWriteConn) Write(b []byte) (int, error) {
+	if c.done {
+		return c.TCPConn.Write(b)
+	}
+	c.done = true
+	if err := c.write(c.TCPConn, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Splits the ClientHello into two tcp segments in the middle of the sni (or in the middle of the hello if there is no sni).
+func HelloSplitWrite(delay time.Duration) HelloWriteFunc {
+	return func(conn *net.TCPConn, hello []byte) error {
+		conn.SetNoDelay(true)
+		pos := helloSplitPos(hello)
+		if _, err := conn.Write(hello[:pos]); err != nil {
+			return err
+		}
+		time.Sleep(delay)
+		_, err := conn.Write(hello[pos:])
+		return err
+	}
+}
+
+// Splits the ClientHello into two tls records in the middle of the sni; both are sent with one write.
+func HelloRecordSplitWrite() HelloWriteFunc {
+	return func(conn *net.TCPConn, hello []byte) error {
+		const hdrLen = 5
+		if len(hello) <= hdrLen+1 {
+			_, err := conn.Write(hello)
+			return err
+		}
+
+		pos := helloSplitPos(hello)
+		hdr := hello[:hdrLen]
+		out := make([]byte, 0, len(hello)+hdrLen)
+		out = append(out, hdr[:3]...)
+		out = binary.BigEndian.AppendUint16(out, uint16(pos-hdrLen))
+		out = append(out, hello[hdrLen:pos]...)
+		out = append(out, hdr[:3]...)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(hello)-pos))
+		out = append(out, hello[pos:]...)
+		_, err := conn.Write(out)
+		return err
+	}
+}
+
+// Writes the ClientHello in chunks of chunkSize bytes (as a rule, each one is a separate tcp segment).
+func HelloChunkedWrite(chunkSize int, delay time.Duration) HelloWriteFunc {
+	return func(conn *net.TCPConn, hello []byte) error {
+		conn.SetNoDelay(true)
+		chunkSize := max(chunkSize, 1)
+		for i := 0; i < len(hello); i += chunkSize {
+			if i > 0 && delay > 0 {
+				time.Sleep(delay)
+			}
+			if _, err := conn.Write(hello[i:min(i+chunkSize, len(hello))]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Returns a ClientHello record (as it is sent into the tcp connection) for the given sni and fingerprint;
+// no connection is made, so it can be sent to a non-tls endpoint as is (e.g. to probe a dpi).
+func UTlsHelloRecord(sni string, id tls.ClientHelloID) ([]byte, error) {
+	uconn := tls.UClient(nil, &tls.Config{ServerName: sni}, id)
+	if err := uconn.BuildHandshakeState(); err != nil {
+		return nil, err
+	}
+
+	raw := uconn.HandshakeState.Hello.Raw
+	record := []byte{0x16, 0x03, 0x01} // handshake, tls 1.0 (as browsers do)
+	record = binary.BigEndian.AppendUint16(record, uint16(len(raw)))
+	return append(record, raw...), nil
+}
+
+// Returns the split position of the ClientHello record: the middle of the sni, or the middle of the record.
+func helloSplitPos(hello []byte) int {
+	if start, n, ok := HelloSniOffset(hello); ok && n > 1 {
+		return start + n/2
+	}
+	return 5 + (len(hello)-5)/2
+}
+
+// Returns offset and length of the server name in the ClientHello record.
+func HelloSniOffset(hello []byte) (int, int, bool) {
+	// record header (5), handshake header (4), version (2), random (32)
+	pos := 5 + 4 + 2 + 32
+	skip := func(lenBytes int) bool {
+		if pos+lenBytes > len(hello) {
+			return false
+		}
+		n := 0
+		for _, b := range hello[pos : pos+lenBytes] {
+			n = n<<8 | int(b)
+		}
+		pos += lenBytes + n
+		return pos <= len(hello)
+	}
+	// session id, cipher suites, compression methods
+	if !skip(1) || !skip(2) || !skip(1) {
+		return 0, 0, false
+	}
+
+	pos += 2 // extensions length
+	for pos+4 <= len(hello) {
+		extType := binary.BigEndian.Uint16(hello[pos:])
+		extLen := int(binary.BigEndian.Uint16(hello[pos+2:]))
+		pos += 4
+		if pos+extLen > len(hello) {
+			return 0, 0, false
+		}
+		// server_name: list length (2), name type (1), name length (2), name
+		if extType == 0 && extLen >= 5 {
+			n := int(binary.BigEndian.Uint16(hello[pos+3:]))
+			if pos+5+n > len(hello) {
+				return 0, 0, false
+			}
+			return pos + 5, n, true
+		}
+		pos += extLen
+	}
+
+	return 0, 0, false
+}
+
+// Sets a fixed padding extension (of n bytes) in spec; it is placed before pre_shared_key, which must be the last one.
+func setUTlsPadding(spec *tls.ClientHelloSpec, n int) {
+	padding := &tls.UtlsPaddingExtension{PaddingLen: n, WillPad: true}
+	for i := range spec.Extensions {
+		if _, ok := spec.Extensions[i].(*tls.UtlsPaddingExtension); ok {
+			spec.Extensions[i] = padding
+			return
+		}
+	}
+
+	last := len(spec.Extensions) - 1
+	if last >= 0 {
+		if _, ok := spec.Extensions[last].(tls.PreSharedKeyExtension); ok {
+			spec.Extensions = slices.Insert(spec.Extensions, last, tls.TLSExtension(padding))
+			return
+		}
+	}
+	spec.Extensions = append(spec.Extensions, padding)
+}
@@ -0,0 +1,79 @@
+package inetutil
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+func TestHelloSniOffset(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	var hello []byte
+	capture := func(conn *net.TCPConn, b []byte) error {
+		hello = append([]byte{}, b...)
+		_, err := conn.Write(b)
+		return err
+	}
+	addr, _ := tlsTestHelloLen(t)
+	GetHandshakedUTlsConn(TlsConnOpt{
+		Ip:                  addr.Addr(),
+		Port:                int(addr.Port()),
+		Sni:                 "sni.example.com",
+		TlsHandshakeTimeout: 300 * time.Millisecond,
+		HelloWrite:          capture,
+	})
+
+	start, n, ok := HelloSniOffset(hello)
+	if !ok || string(hello[start:start+n]) != "sni.example.com" {
+		t.Fatalf("got offset %d, len %d, ok %v", start, n, ok)
+	}
+}
+
+func TestUTlsHelloRecord(t *testing.T) {
+	hello, err := UTlsHelloRecord("sni.example.com", *Fingerprints["chrome"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start, n, ok := HelloSniOffset(hello)
+	if !ok || string(hello[start:start+n]) != "sni.example.com" {
+		t.Fatalf("got offset %d, len %d, ok %v", start, n, ok)
+	}
+}
+
+func TestHelloWriteHandshake(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	tests := map[string]TlsConnOpt{
+		"tcp split":     {HelloWrite: HelloSplitWrite(10 * time.Millisecond)},
+		"record split":  {HelloWrite: HelloRecordSplitWrite()},
+		"tiny segments": {HelloWrite: HelloChunkedWrite(8, 0)},
+		"padding":       {HelloPadding: 1500},
+	}
+	for name, opt := range tests {
+		opt.Ip, opt.Port, opt.Sni = addr.Addr(), int(addr.Port()), "example.com"
+		opt.TlsHandshakeTimeout = 3 * time.Second
+		conn, err := GetHandshakedUTlsConn(opt)
+		if err != nil {
+			t.Fatalf("%s: got %v, want nil", name, err)
+		}
+		conn.Close()
+	}
+}
@@ -0,0 +1,193 @@
+package inetutil
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+var (
+	ErrQuicHandshakeTimeout   = errors.New("quic: handshake timeout")
+	ErrQuicHandshakeFail      = errors.New("quic: handshake failure")
+	ErrQuicVersionNegotiation = errors.New("quic: version negotiation failure")
+	ErrQuicStatelessReset     = errors.New("quic: stateless reset")
+	ErrQuicStreamTimeout      = errors.New("quic: stream timeout")
+	ErrQuicStreamClosed       = errors.New("quic: stream closed")
+)
+
+type QuicConnOpt struct {
+	Ctx              context.Context
+	Ip               netip.Addr
+	Port             int
+	Sni              string
+	HandshakeTimeout time.Duration
+	Versions         []quic.Version // empty is quic-go defaults (v1, v2)
+	Alpn             []string       // empty is h3
+	InsecureVerify   bool
+}
+
+// Handshaked quic connection with its own udp socket (closed along with the connection).
+type QuicConn struct {
+	*quic.Conn
+	tr *quic.Transport
+}
+
+func (c *QuicConn) Close() error {
+	err := c.CloseWithError(0, "")
+	c.tr.Close()
+	return err
+}
+
+// Performs a quic (Initial + tls 1.3) handshake; the udp socket is bound to the network interface from config.
+func GetHandshakedQuicConn(opt QuicConnOpt) (*QuicConn, error) {
+	ctx := opt.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opt.HandshakeTimeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.HandshakeTimeout)
+		defer cancel()
+	}
+
+	udpConn, err := net.ListenUDP("udp", quicDefaultLocalAddr())
+	if err != nil {
+		log.Println("getHandshakedQuicConn/ListenUDP", err)
+		return nil, ErrInternal
+	}
+	tr := &quic.Transport{Conn: udpConn}
+
+	tlsConf := &tls.Config{
+		InsecureSkipVerify: !opt.InsecureVerify,
+		ServerName:         opt.Sni,
+		NextProtos:         opt.Alpn,
+		KeyLogWriter:       KeyLogWriter(),
+	}
+	if len(tlsConf.NextProtos) == 0 {
+		tlsConf.NextProtos = []string{"h3"}
+	}
+
+	quicConf := &quic.Config{Versions: opt.Versions, HandshakeIdleTimeout: opt.HandshakeTimeout}
+	addr := &net.UDPAddr{IP: net.IP(opt.Ip.AsSlice()), Port: opt.Port}
+	conn, err := tr.Dial(ctx, addr, tlsConf, quicConf)
+	if err != nil {
+		tr.Close()
+		return nil, quicHandleErr(err)
+	}
+
+	return &QuicConn{Conn: conn, tr: tr}, nil
+}
+
+func quicHandleErr(err error) error {
+	if _, ok := errors.AsType[*quic.VersionNegotiationError](err); ok {
+		return ErrQuicVersionNegotiation
+	}
+	if _, ok := errors.AsType[*quic.StatelessResetError](err); ok {
+		return ErrQuicStatelessReset
+	}
+	if isTimeoutErr(err) {
+		return ErrQuicHandshakeTimeout
+	}
+	if _, ok := errors.AsType[*tls.CertificateVerificationError](err); ok {
+		return ErrTlsCertificateInvalid
+	}
+	if e, ok := errors.AsType[*quic.TransportError](err); ok && (e.ErrorCode.IsCryptoError() || e.Remote) {
+		return ErrQuicHandshakeFail
+	}
+	if _, ok := errors.AsType[*quic.ApplicationError](err); ok {
+		return ErrQuicHandshakeFail
+	}
+
+	log.Println("getHandshakedQuicConn/Dial", err)
+	return ErrInternal
+}
+
+// RFC 9250: one query per stream, with the same two-byte length framing as dns over tcp.
+// The query message id must be 0.
+func QuicDnsExchange(ctx context.Context, conn *QuicConn, query []byte) ([]byte, error) {
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, quicStreamHandleErr(err, "QuicDnsExchange/OpenStreamSync")
+	}
+	defer stream.CancelRead(0)
+	defer ctxDeadline(ctx, stream.SetDeadline)()
+
+	msg := binary.BigEndian.AppendUint16(nil, uint16(len(query)))
+	if _, err := stream.Write(append(msg, query...)); err != nil {
+		return nil, quicStreamHandleErr(err, "QuicDnsExchange/Write")
+	}
+	// the client must indicate that no more queries will be sent on the stream
+	if err := stream.Close(); err != nil {
+		return nil, quicStreamHandleErr(err, "QuicDnsExchange/Close")
+	}
+
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(stream, hdr); err != nil {
+		return nil, quicStreamHandleErr(err, "QuicDnsExchange/Read")
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(hdr))
+	if _, err := io.ReadFull(stream, resp); err != nil {
+		return nil, quicStreamHandleErr(err, "QuicDnsExchange/Read")
+	}
+	return resp, nil
+}
+
+// Sends the http/3 request over the connection (the connection must be handshaked with the h3 alpn).
+// Reads at most maxBodyLen bytes of the response body.
+func QuicHttp3Request(ctx context.Context, conn *QuicConn, req *http.Request, maxBodyLen int64) (*http.Response, []byte, error) {
+	cc := (&http3.Transport{}).NewClientConn(conn.Conn)
+
+	resp, err := cc.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, quicStreamHandleErr(err, "QuicHttp3Request/RoundTrip")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyLen))
+	if err != nil {
+		return nil, nil, quicStreamHandleErr(err, "QuicHttp3Request/ReadBody")
+	}
+	return resp, body, nil
+}
+
+func quicStreamHandleErr(err error, logPrefix string) error {
+	if isTimeoutErr(err) {
+		return ErrQuicStreamTimeout
+	}
+	if _, ok := errors.AsType[*quic.StatelessResetError](err); ok {
+		return ErrQuicStatelessReset
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrQuicStreamClosed
+	}
+	if _, ok := errors.AsType[*quic.StreamError](err); ok {
+		return ErrQuicStreamClosed
+	}
+	if _, ok := errors.AsType[*quic.ApplicationError](err); ok {
+		return ErrQuicStreamClosed
+	}
+	if _, ok := errors.AsType[*quic.TransportError](err); ok {
+		return ErrQuicStreamClosed
+	}
+
+	log.Println(logPrefix, err)
+	return ErrInternal
+}
+
+// Returns default udp local address for quic connections, considering network interface options in config.
+func quicDefaultLocalAddr() *net.UDPAddr {
+	if tcpAddr, ok := tlsDefaultDialerLocalAddr().(*net.TCPAddr); ok && tcpAddr != nil {
+		return &net.UDPAddr{IP: tcpAddr.IP}
+	}
+	return &net.UDPAddr{}
+}
@@ -0,0 +1,193 @@
+package inetutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Starts a local quic server stand-in; returns its address.
+func quicTestServer(t *testing.T, versions []quic.Version) netip.AddrPort {
+	t.Helper()
+	return quicTestServerWith(t, versions, func(*quic.Conn) {})
+}
+
+// Like quicTestServer, but every accepted connection is passed to handle.
+func quicTestServerWith(t *testing.T, versions []quic.Version, handle func(*quic.Conn)) netip.AddrPort {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "quic.test"},
+		DNSNames:     []string{"quic.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConf := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		NextProtos:   []string{"h3", "doq"},
+	}
+	ln, err := quic.ListenAddr("127.0.0.1:0", tlsConf, &quic.Config{Versions: versions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+
+	return ln.Addr().(*net.UDPAddr).AddrPort()
+}
+
+func TestQuicHandshake(t *testing.T) {
+	addr := quicTestServer(t, nil)
+	conn, err := GetHandshakedQuicConn(QuicConnOpt{
+		Ip:               addr.Addr(),
+		Port:             int(addr.Port()),
+		Sni:              "quic.test",
+		HandshakeTimeout: 3 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	defer conn.Close()
+
+	if got := conn.ConnectionState().TLS.NegotiatedProtocol; got != "h3" {
+		t.Fatalf("got alpn %q, want h3", got)
+	}
+}
+
+func TestQuicVersionNegotiation(t *testing.T) {
+	addr := quicTestServer(t, []quic.Version{quic.Version1})
+	_, err := GetHandshakedQuicConn(QuicConnOpt{
+		Ip:               addr.Addr(),
+		Port:             int(addr.Port()),
+		Sni:              "quic.test",
+		HandshakeTimeout: 3 * time.Second,
+		Versions:         []quic.Version{quic.Version2},
+	})
+	if err != ErrQuicVersionNegotiation {
+		t.Fatalf("got %v, want %v", err, ErrQuicVersionNegotiation)
+	}
+}
+
+func TestQuicHandshakeFail(t *testing.T) {
+	addr := quicTestServer(t, nil)
+	_, err := GetHandshakedQuicConn(QuicConnOpt{
+		Ip:               addr.Addr(),
+		Port:             int(addr.Port()),
+		Sni:              "quic.test",
+		HandshakeTimeout: 3 * time.Second,
+		Alpn:             []string{"unknown"},
+	})
+	if err != ErrQuicHandshakeFail {
+		t.Fatalf("got %v, want %v", err, ErrQuicHandshakeFail)
+	}
+}
+
+func TestQuicHandshakeTimeout(t *testing.T) {
+	// udp socket that never responds (i.e. quic is dropped)
+	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udpConn.Close()
+	addr := udpConn.LocalAddr().(*net.UDPAddr).AddrPort()
+
+	_, err = GetHandshakedQuicConn(QuicConnOpt{
+		Ip:               addr.Addr(),
+		Port:             int(addr.Port()),
+		Sni:              "quic.test",
+		HandshakeTimeout: 500 * time.Millisecond,
+	})
+	if err != ErrQuicHandshakeTimeout {
+		t.Fatalf("got %v, want %v", err, ErrQuicHandshakeTimeout)
+	}
+}
+
+func TestQuicDnsExchange(t *testing.T) {
+	// echoes the framed query back after the client closes its side of the stream
+	addr := quicTestServerWith(t, nil, func(conn *quic.Conn) {
+		stream, err := conn.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+		msg, _ := io.ReadAll(stream)
+		stream.Write(msg)
+		stream.Close()
+	})
+	conn, err := GetHandshakedQuicConn(QuicConnOpt{
+		Ip:               addr.Addr(),
+		Port:             int(addr.Port()),
+		Sni:              "quic.test",
+		HandshakeTimeout: 3 * time.Second,
+		Alpn:             []string{"doq"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	query := []byte("query")
+	resp, err := QuicDnsExchange(ctx, conn, query)
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	if !bytes.Equal(resp, query) {
+		t.Fatalf("got %q, want %q", resp, query)
+	}
+}
+
+func TestQuicDnsExchangeTimeout(t *testing.T) {
+	// accepts the stream, but never answers
+	addr := quicTestServerWith(t, nil, func(conn *quic.Conn) {
+		conn.AcceptStream(context.Background())
+	})
+	conn, err := GetHandshakedQuicConn(QuicConnOpt{
+		Ip:               addr.Addr(),
+		Port:             int(addr.Port()),
+		Sni:              "quic.test",
+		HandshakeTimeout: 3 * time.Second,
+		Alpn:             []string{"doq"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := QuicDnsExchange(ctx, conn, []byte("query")); err != ErrQuicStreamTimeout {
+		t.Fatalf("got %v, want %v", err, ErrQuicStreamTimeout)
+	}
+}
@@ -0,0 +1,42 @@
+package inetutil
+
+import (
+	"io"
+	"time"
+)
+
+// Records the cumulative number of bytes read at the end of each interval (counted from Start or from the first Read).
+// Intervals without reads (e.g. a freeze) are filled in by the next Read or by Flush.
+type SamplingReader struct {
+	Reader   io.Reader
+	Interval time.Duration
+	Samples  []int64
+	Bytes    int64
+
+	start time.Time
+}
+
+func (r *SamplingReader) Start() {
+	r.start = time.Now()
+}
+
+func (r *SamplingReader) Read(p []byte) (int, error) {
+	if r.start.IsZero() {
+		r.Start()
+	}
+	n, err := r.Reader.Read(p)
+	// the data has arrived during the read, so the intervals that are already over don't include it
+	r.Flush(time.Now())
+	r.Bytes += int64(n)
+	return n, err
+}
+
+// Appends samples for all intervals that are over by t.
+func (r *SamplingReader) Flush(t time.Time) {
+	if r.start.IsZero() || r.Interval <= 0 {
+		return
+	}
+	for done := int(t.Sub(r.start) / r.Interval); len(r.Samples) < done; {
+		r.Samples = append(r.Samples, r.Bytes)
+	}
+}
@@ -0,0 +1,156 @@
+package inetutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/netip"
+)
+
+var ErrStunMalformed = errors.New("stun: malformed response")
+
+const (
+	stunMagicCookie      = 0x2112a442
+	stunBindingRequest   = 0x0001
+	stunBindingSuccess   = 0x0101
+	stunAttrMapped       = 0x0001
+	stunAttrXorMapped    = 0x0020
+	stunHeaderLen        = 20
+	stunTransactionIdLen = 12
+)
+
+// Returns stun (rfc 5389) Binding request without attributes and its transaction id.
+func StunBindingRequest() ([]byte, []byte) {
+	msg := make([]byte, stunHeaderLen)
+	binary.BigEndian.PutUint16(msg[0:], stunBindingRequest)
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	rand.Read(msg[8:stunHeaderLen])
+	return msg, msg[8:stunHeaderLen]
+}
+
+// Returns the mapped address (xor-mapped is preferred) from stun Binding success response.
+func ParseStunBindingResponse(msg, txId []byte) (netip.AddrPort, error) {
+	if len(msg) < stunHeaderLen ||
+		binary.BigEndian.Uint16(msg[0:]) != stunBindingSuccess ||
+		binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie ||
+		!bytes.Equal(msg[8:stunHeaderLen], txId) {
+		return netip.AddrPort{}, ErrStunMalformed
+	}
+
+	attrs := msg[stunHeaderLen:min(len(msg), stunHeaderLen+int(binary.BigEndian.Uint16(msg[2:])))]
+	var mapped netip.AddrPort
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+
+		val := attrs[4 : 4+attrLen]
+		switch attrType {
+		case stunAttrXorMapped:
+			if addr, ok := stunParseAddr(val, msg[4:stunHeaderLen]); ok {
+				return addr, nil
+			}
+		case stunAttrMapped:
+			if addr, ok := stunParseAddr(val, nil); ok {
+				mapped = addr
+			}
+		}
+		attrs = attrs[min(len(attrs), 4+(attrLen+3)/4*4):] // attributes are padded to 4 bytes
+	}
+
+	if !mapped.IsValid() {
+		return netip.AddrPort{}, ErrStunMalformed
+	}
+	return mapped, nil
+}
+
+// Parses (xor-)mapped address attribute value; xor is the magic cookie + transaction id (nil if not xor-ed).
+func stunParseAddr(val, xor []byte) (netip.AddrPort, bool) {
+	// reserved (1), family (1), port (2), address (4 or 16)
+	if len(val) < 8 {
+		return netip.AddrPort{}, false
+	}
+
+	port := binary.BigEndian.Uint16(val[2:])
+	ip := bytes.Clone(val[4:])
+	if xor != nil {
+		port ^= stunMagicCookie >> 16
+		for i := range ip {
+			ip[i] ^= xor[i]
+		}
+	}
+
+	switch {
+	case val[1] == 0x01 && len(ip) == 4, val[1] == 0x02 && len(ip) == 16:
+		addr, _ := netip.AddrFromSlice(ip)
+		return netip.AddrPortFrom(addr.Unmap(), port), true
+	}
+	return netip.AddrPort{}, false
+}
+
+// Sends stun Binding request via conn (unconnected udp socket) to addr and waits for the response;
+// responses with other transaction ids (e.g. late ones) are skipped.
+func StunBindingUdp(ctx context.Context, conn *net.UDPConn, addr netip.AddrPort) (netip.AddrPort, error) {
+	defer ctxDeadline(ctx, conn.SetDeadline)()
+
+	req, txId := StunBindingRequest()
+	if _, err := conn.WriteToUDPAddrPort(req, addr); err != nil {
+		return netip.AddrPort{}, udpHandleErr(err, "StunBindingUdp/Write")
+	}
+
+	b := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFromUDPAddrPort(b)
+		if err != nil {
+			return netip.AddrPort{}, udpHandleErr(err, "StunBindingUdp/Read")
+		}
+		if from.Addr().Unmap() != addr.Addr() {
+			continue
+		}
+		if mapped, err := ParseStunBindingResponse(b[:n], txId); err == nil {
+			return mapped, nil
+		}
+	}
+}
+
+// Sends stun Binding request via tcp conn and reads the response (stun messages are framed by their length).
+func StunBindingTcp(ctx context.Context, conn net.Conn) (netip.AddrPort, error) {
+	defer ctxDeadline(ctx, conn.SetDeadline)()
+
+	req, txId := StunBindingRequest()
+	if _, err := conn.Write(req); err != nil {
+		return netip.AddrPort{}, stunTcpHandleErr(err, ErrTcpWriteTimeout, "StunBindingTcp/Write")
+	}
+
+	resp := make([]byte, stunHeaderLen)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return netip.AddrPort{}, stunTcpHandleErr(err, ErrTcpReadTimeout, "StunBindingTcp/Read")
+	}
+	resp = append(resp, make([]byte, binary.BigEndian.Uint16(resp[2:]))...)
+	if _, err := io.ReadFull(conn, resp[stunHeaderLen:]); err != nil {
+		return netip.AddrPort{}, stunTcpHandleErr(err, ErrTcpReadTimeout, "StunBindingTcp/Read")
+	}
+
+	return ParseStunBindingResponse(resp, txId)
+}
+
+func stunTcpHandleErr(err, timeoutErr error, logPrefix string) error {
+	if isTimeoutErr(err) {
+		return timeoutErr
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTcpConnClosed
+	}
+	if handledErr, ok := tryHandleErr(err); ok {
+		return handledErr
+	}
+	log.Println(logPrefix, err)
+	return ErrInternal
+}
@@ -0,0 +1,117 @@
+package inetutil
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// Returns Binding success response to req with xor-mapped address of from.
+func stunTestResponse(req []byte, from netip.AddrPort) []byte {
+	resp := make([]byte, stunHeaderLen, stunHeaderLen+12)
+	binary.BigEndian.PutUint16(resp[0:], stunBindingSuccess)
+	binary.BigEndian.PutUint16(resp[2:], 12)
+	copy(resp[4:], req[4:stunHeaderLen])
+
+	ip := from.Addr().Unmap().As4()
+	val := []byte{0, 0x01, 0, 0, ip[0], ip[1], ip[2], ip[3]}
+	binary.BigEndian.PutUint16(val[2:], from.Port()^stunMagicCookie>>16)
+	for i := range 4 {
+		val[4+i] ^= req[4+i]
+	}
+	resp = binary.BigEndian.AppendUint16(resp, stunAttrXorMapped)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(val)))
+	return append(resp, val...)
+}
+
+func TestParseStunBindingResponse(t *testing.T) {
+	// rfc 5769, 2.2: sample ipv4 response
+	msg := []byte{
+		0x01, 0x01, 0x00, 0x3c, 0x21, 0x12, 0xa4, 0x42,
+		0xb7, 0xe7, 0xa7, 0x01, 0xbc, 0x34, 0xd6, 0x86, 0xfa, 0x87, 0xdf, 0xae,
+		0x80, 0x22, 0x00, 0x0b, 0x74, 0x65, 0x73, 0x74, 0x20, 0x76, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x20,
+		0x00, 0x20, 0x00, 0x08, 0x00, 0x01, 0xa1, 0x47, 0xe1, 0x12, 0xa6, 0x43,
+		0x00, 0x08, 0x00, 0x14, 0x2b, 0x91, 0xf5, 0x99, 0xfd, 0x9e, 0x90, 0xc3, 0x8c, 0x74,
+		0x89, 0xf9, 0x2a, 0xf9, 0xba, 0x53, 0xf0, 0x6b, 0xe7, 0xd7,
+		0x80, 0x28, 0x00, 0x04, 0xc0, 0x7d, 0x4c, 0x96,
+	}
+
+	mapped, err := ParseStunBindingResponse(msg, msg[8:20])
+	if err != nil || mapped != netip.MustParseAddrPort("192.0.2.1:32853") {
+		t.Fatalf("got %v, %v", mapped, err)
+	}
+	if _, err := ParseStunBindingResponse(msg, make([]byte, 12)); err != ErrStunMalformed {
+		t.Fatalf("other transaction id: got %v, want %v", err, ErrStunMalformed)
+	}
+}
+
+func TestStunBinding(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	go func() {
+		b := make([]byte, 1500)
+		for {
+			n, from, err := udp.ReadFromUDPAddrPort(b)
+			if err != nil {
+				return
+			}
+			udp.WriteToUDPAddrPort(stunTestResponse(b[:n], from), from)
+		}
+	}()
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	go func() {
+		conn, err := tcp.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		b := make([]byte, stunHeaderLen)
+		for {
+			if _, err := conn.Read(b); err != nil {
+				return
+			}
+			conn.Write(stunTestResponse(b, conn.RemoteAddr().(*net.TCPAddr).AddrPort()))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	conn, err := GetUdpListener()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	mapped, err := StunBindingUdp(ctx, conn, udp.LocalAddr().(*net.UDPAddr).AddrPort())
+	if err != nil || mapped.Port() != conn.LocalAddr().(*net.UDPAddr).AddrPort().Port() {
+		t.Fatalf("udp: got %v, %v", mapped, err)
+	}
+
+	addr := tcp.Addr().(*net.TCPAddr).AddrPort()
+	tcpConn, err := GetTcpConn(TcpConnOpt{Ip: addr.Addr(), Port: int(addr.Port())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpConn.Close()
+	mapped, err = StunBindingTcp(ctx, tcpConn)
+	if err != nil || mapped != tcpConn.LocalAddr().(*net.TCPAddr).AddrPort() {
+		t.Fatalf("tcp: got %v, %v", mapped, err)
+	}
+}
@@ -0,0 +1,205 @@
+package inetutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+type TcpConnOpt struct {
+	Ctx            context.Context
+	Ip             netip.Addr
+	Port           int
+	TcpConnTimeout time.Duration
+	TcpWriteBuf    int
+	TcpReadBuf     int
+}
+
+// Returns established tcp connection (e.g. for plain http); the network interface from config is considered.
+func GetTcpConn(opt TcpConnOpt) (*net.TCPConn, error) {
+	tcpDialer := net.Dialer{LocalAddr: tlsDefaultDialerLocalAddr()}
+	if opt.TcpConnTimeout != 0 {
+		tcpDialer.Timeout = opt.TcpConnTimeout
+	}
+
+	ctx := opt.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	addr := net.JoinHostPort(opt.Ip.String(), strconv.Itoa(opt.Port))
+	tcpConn, err := tcpDialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		if isTimeoutErr(err) {
+			return nil, ErrTcpConnTimeout
+		}
+		if handledErr, ok := tryHandleErr(err); ok {
+			return nil, handledErr
+		}
+
+		log.Println("getTcpConn/Dial", err)
+		return nil, ErrInternal
+	}
+
+	rawTcpConn := tcpConn.(*net.TCPConn)
+	if opt.TcpWriteBuf != 0 {
+		rawTcpConn.SetWriteBuffer(opt.TcpWriteBuf)
+	}
+	if opt.TcpReadBuf != 0 {
+		rawTcpConn.SetReadBuffer(opt.TcpReadBuf)
+	}
+
+	return rawTcpConn, nil
+}
+
+// Writes b into conn and waits for the first bytes of the response; returns the number of bytes read.
+// If the peer closes the connection without any data, ErrTcpConnClosed is returned.
+func TcpWriteRead(ctx context.Context, conn net.Conn, b []byte) (int, error) {
+	defer ctxDeadline(ctx, conn.SetDeadline)()
+
+	if _, err := conn.Write(b); err != nil {
+		if isTimeoutErr(err) {
+			return 0, ErrTcpWriteTimeout
+		}
+		if handledErr, ok := tryHandleErr(err); ok {
+			return 0, handledErr
+		}
+		log.Println("TcpWriteRead/Write", err)
+		return 0, ErrInternal
+	}
+
+	n, err := conn.Read(make([]byte, 4096))
+	if n > 0 {
+		return n, nil
+	}
+	if err == io.EOF {
+		return 0, ErrTcpConnClosed
+	}
+	if isTimeoutErr(err) {
+		return 0, ErrTcpReadTimeout
+	}
+	if handledErr, ok := tryHandleErr(err); ok {
+		return 0, handledErr
+	}
+	log.Println("TcpWriteRead/Read", err)
+	return 0, ErrInternal
+}
+
+// Writes the dns query and reads the response, both are prefixed with a two byte length field
+// (dns over tcp, RFC 7766; DoT, RFC 7858); conn may be a tls connection.
+func TcpDnsExchange(ctx context.Context, conn net.Conn, query []byte) ([]byte, error) {
+	defer ctxDeadline(ctx, conn.SetDeadline)()
+
+	msg := binary.BigEndian.AppendUint16(nil, uint16(len(query)))
+	if _, err := conn.Write(append(msg, query...)); err != nil {
+		if isTimeoutErr(err) {
+			return nil, ErrTcpWriteTimeout
+		}
+		if handledErr, ok := tryHandleErr(err); ok {
+			return nil, handledErr
+		}
+		log.Println("TcpDnsExchange/Write", err)
+		return nil, ErrInternal
+	}
+
+	readErr := func(err error) error {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrTcpConnClosed
+		}
+		if isTimeoutErr(err) {
+			return ErrTcpReadTimeout
+		}
+		if handledErr, ok := tryHandleErr(err); ok {
+			return handledErr
+		}
+		log.Println("TcpDnsExchange/Read", err)
+		return ErrInternal
+	}
+
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(conn, hdr); err != nil {
+		return nil, readErr(err)
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(hdr))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, readErr(err)
+	}
+	return resp, nil
+}
+
+func TcpReadHttpResponse(ctx context.Context, conn net.Conn, br *bufio.Reader) (*http.Response, error) {
+	return connReadHttpResponse(ctx, conn, br, "TcpReadHttpResponse")
+}
+
+// Reads (and discards) the http response body; returns the number of bytes read.
+func TcpReadHttpBody(ctx context.Context, conn net.Conn, body io.Reader) (int64, error) {
+	return connReadHttpBody(ctx, conn, body, "TcpReadHttpBody")
+}
+
+func TcpWriteHttpRequest(ctx context.Context, conn net.Conn, req *http.Request) (int64, error) {
+	return connWriteHttpRequest(ctx, conn, req, "TcpHttpRequest")
+}
+
+func connReadHttpResponse(ctx context.Context, conn net.Conn, br *bufio.Reader, logPrefix string) (*http.Response, error) {
+	defer ctxDeadline(ctx, conn.SetReadDeadline)()
+
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		if isTimeoutErr(err) {
+			return nil, ErrTcpReadTimeout
+		}
+		if handledErr, ok := tryHandleErr(err); ok {
+			return nil, handledErr
+		}
+		log.Println(logPrefix, err)
+		return nil, ErrInternal
+	}
+	return resp, nil
+}
+
+func connReadHttpBody(ctx context.Context, conn net.Conn, body io.Reader, logPrefix string) (int64, error) {
+	defer ctxDeadline(ctx, conn.SetReadDeadline)()
+
+	n, err := io.Copy(io.Discard, body)
+	if err != nil {
+		if isTimeoutErr(err) {
+			return n, ErrTcpReadTimeout
+		}
+		if handledErr, ok := tryHandleErr(err); ok {
+			return n, handledErr
+		}
+		log.Println(logPrefix, err)
+		return n, ErrInternal
+	}
+	return n, nil
+}
+
+func connWriteHttpRequest(ctx context.Context, conn net.Conn, req *http.Request, logPrefix string) (int64, error) {
+	defer ctxDeadline(ctx, conn.SetWriteDeadline)()
+
+	var writeBuf bytes.Buffer
+	if err := req.Write(&writeBuf); err != nil {
+		return 0, err
+	}
+	n := int64(writeBuf.Len())
+
+	if _, err := conn.Write(writeBuf.Bytes()); err != nil {
+		if isTimeoutErr(err) {
+			return 0, ErrTcpWriteTimeout
+		}
+		if handledErr, ok := tryHandleErr(err); ok {
+			return 0, handledErr
+		}
+		log.Println(logPrefix, err)
+		return 0, ErrInternal
+	}
+	return n, nil
+}
@@ -0,0 +1,125 @@
+package inetutil
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// Accepts connections and reacts to the first read with handle.
+func tcpTestServer(t *testing.T, handle func(conn *net.TCPConn)) netip.AddrPort {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if _, err := conn.Read(make([]byte, 4096)); err != nil {
+					return
+				}
+				handle(conn.(*net.TCPConn))
+			}()
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).AddrPort()
+}
+
+func TestTcpWriteRead(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		handle func(conn *net.TCPConn)
+		want   error
+	}{
+		"answered": {func(conn *net.TCPConn) { conn.Write([]byte("pong")) }, nil},
+		"closed":   {func(conn *net.TCPConn) {}, ErrTcpConnClosed},
+		"reset":    {func(conn *net.TCPConn) { conn.SetLinger(0) }, ErrTcpConnReset},
+		"silent":   {func(conn *net.TCPConn) { io.Copy(io.Discard, conn) }, ErrTcpReadTimeout},
+	}
+	for name, tt := range tests {
+		addr := tcpTestServer(t, tt.handle)
+		conn, err := GetTcpConn(TcpConnOpt{Ip: addr.Addr(), Port: int(addr.Port())})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		_, err = TcpWriteRead(ctx, conn, []byte("ping"))
+		cancel()
+		conn.Close()
+		if err != tt.want {
+			t.Fatalf("%s: got %v, want %v", name, err, tt.want)
+		}
+	}
+}
+
+func TestTcpConnRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().(*net.TCPAddr).AddrPort()
+	ln.Close()
+
+	_, err = GetTcpConn(TcpConnOpt{Ip: addr.Addr(), Port: int(addr.Port())})
+	if err != ErrTcpConnRefused {
+		t.Fatalf("got %v, want %v", err, ErrTcpConnRefused)
+	}
+}
+
+func TestTcpDnsExchange(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := tcpTestServer(t, func(conn *net.TCPConn) {})
+
+	// the first read gets the whole framed query (it is written at once), so it is echoed as is
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		b := make([]byte, 4096)
+		n, _ := conn.Read(b)
+		conn.Write(b[:n])
+	}()
+
+	for addr, want := range map[netip.AddrPort]error{ln.Addr().(*net.TCPAddr).AddrPort(): nil, closed: ErrTcpConnClosed} {
+		conn, err := GetTcpConn(TcpConnOpt{Ip: addr.Addr(), Port: int(addr.Port())})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		resp, err := TcpDnsExchange(ctx, conn, []byte("query"))
+		cancel()
+		conn.Close()
+		if err != want || (err == nil && string(resp) != "query") {
+			t.Fatalf("%v: got %q, %v; want %v", addr, resp, err, want)
+		}
+	}
+}
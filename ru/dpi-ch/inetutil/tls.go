@@ -2,7 +2,6 @@ package inetutil
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -12,7 +11,7 @@ import (
 	"net/http"
 	"net/netip"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -24,8 +23,10 @@ import (
 var (
 	ErrTcpConnReset          = errors.New("tcp: connection reset")
 	ErrTcpConnTimeout        = errors.New("tcp: connection timeout")
+	ErrTcpConnRefused        = errors.New("tcp: connection refused")
 	ErrTcpWriteTimeout       = errors.New("tcp: write timeout")
 	ErrTcpReadTimeout        = errors.New("tcp: read timeout")
+	ErrTcpConnClosed         = errors.New("tcp: connection closed by peer")
 	ErrTlsCertificateInvalid = errors.New("tls: certificate invalid")
 	ErrTlsHandshakeTimeout   = errors.New("tls: handshake timeout")
 	ErrTlsHandshakeFail      = errors.New("tls: handshake failure")
@@ -52,6 +53,14 @@ var Fingerprints = map[string]*tls.ClientHelloID{
 	"qq":      &tls.HelloQQ_11_1,
 }
 
+type KeyShare int
+
+const (
+	KeyShareDefault KeyShare = iota // as is in the fingerprint
+	KeySharePq                      // X25519MLKEM768 (large ClientHello, usually spans several tcp segments) + classic
+	KeyShareClassic                 // classic only, without post-quantum key shares
+)
+
 type TlsConnOpt struct {
 	Ctx                 context.Context
 	Ip                  netip.Addr
@@ -64,6 +73,11 @@ type TlsConnOpt struct {
 	InsecureVerify      bool
 	ClientHelloId       tls.ClientHelloID
 	OriginalAlpn        bool
+	Alpn                []string // overrides the fingerprint alpn (e.g. "dot"); OriginalAlpn is ignored then
+	KeyShare            KeyShare
+	HelloPadding        int            // padding extension size in bytes; 0 is as is in the fingerprint
+	HelloWrite          HelloWriteFunc // nil is a single write
+	EchConfigList       []byte         // ech is used if set; Sni is the inner (real) one
 }
 
 var keyLogMu sync.Mutex
@@ -88,35 +102,19 @@ func KeyLogWriter() io.Writer {
 	return keyLogWriter
 }
 
-// TODO (options):
-// - Set proto (http/https)
+// For plain http (without tls), see GetTcpConn.
 func GetHandshakedUTlsConn(opt TlsConnOpt) (*tls.UConn, error) {
 	cfg := config.Get().InetUtil
-	tcpDialer := net.Dialer{LocalAddr: tlsDefaultDialerLocalAddr()}
-	if opt.TcpConnTimeout != 0 {
-		tcpDialer.Timeout = opt.TcpConnTimeout
-	}
-
-	addr := net.JoinHostPort(opt.Ip.String(), strconv.Itoa(opt.Port))
-	tcpConn, err := tcpDialer.Dial("tcp", addr)
+	tcpConn, err := GetTcpConn(TcpConnOpt{
+		Ctx:            opt.Ctx,
+		Ip:             opt.Ip,
+		Port:           opt.Port,
+		TcpConnTimeout: opt.TcpConnTimeout,
+		TcpWriteBuf:    opt.TcpWriteBuf,
+		TcpReadBuf:     opt.TcpReadBuf,
+	})
 	if err != nil {
-		if isTimeoutErr(err) {
-			return nil, ErrTcpConnTimeout
-		}
-		if handledErr, ok := tryHandleErr(err); ok {
-			return nil, handledErr
-		}
-
-		log.Println("getHandshakedUTlsConn/Dial", err)
-		return nil, ErrInternal
-	}
-
-	rawTcpConn := tcpConn.(*net.TCPConn)
-	if opt.TcpWriteBuf != 0 {
-		rawTcpConn.SetWriteBuffer(opt.TcpWriteBuf)
-	}
-	if opt.TcpReadBuf != 0 {
-		rawTcpConn.SetReadBuffer(opt.TcpReadBuf)
+		return nil, err
 	}
 
 	tlsConf := &tls.Config{
@@ -127,8 +125,19 @@ func GetHandshakedUTlsConn(opt TlsConnOpt) (*tls.UConn, error) {
 	if opt.Sni != "" {
 		tlsConf.ServerName = opt.Sni
 	}
+	if opt.EchConfigList != nil {
+		tlsConf.EncryptedClientHelloConfigList = opt.EchConfigList
+		if !opt.InsecureVerify {
+			// otherwise, the outer certificate (for public name) is verified on rejection
+			tlsConf.EncryptedClientHelloRejectionVerify = func(tls.ConnectionState) error { return nil }
+		}
+	}
 
-	tlsConn := tls.UClient(tcpConn, tlsConf, tls.HelloCustom)
+	var netConn net.Conn = tcpConn
+	if opt.HelloWrite != nil {
+		netConn = &helloWriteConn{TCPConn: tcpConn, write: opt.HelloWrite}
+	}
+	tlsConn := tls.UClient(netConn, tlsConf, tls.HelloCustom)
 
 	fingerprint := Fingerprints[cfg.Fingerprint]
 	if fingerprint == nil {
@@ -139,11 +148,29 @@ func GetHandshakedUTlsConn(opt TlsConnOpt) (*tls.UConn, error) {
 		spec, _ = tls.UTLSIdToSpec(opt.ClientHelloId)
 	}
 
-	if !opt.OriginalAlpn {
+	if opt.Alpn != nil {
+		// WARN: this change breaks fingerprint
+		setUTlsAlpn(&spec, opt.Alpn)
+	} else if !opt.OriginalAlpn {
 		// WARN: this change breaks fingerprint
 		// make sure that ClientHello does not contain ALPN for h2
 		setUTlsAlpn(&spec, []string{"http/1.1"})
 	}
+	switch opt.KeyShare {
+	case KeySharePq:
+		// WARN: this change breaks fingerprint (if it is not post-quantum already)
+		setUTlsPqKeyShare(&spec)
+	case KeyShareClassic:
+		// WARN: this change breaks fingerprint (if it is post-quantum)
+		removeUTlsPqKeyShare(&spec)
+	}
+	if opt.EchConfigList != nil {
+		setUTlsEch(&spec)
+	}
+	if opt.HelloPadding > 0 {
+		// WARN: this change breaks fingerprint
+		setUTlsPadding(&spec, opt.HelloPadding)
+	}
 	tlsConn.ApplyPreset(&spec)
 
 	if opt.TlsHandshakeTimeout != 0 {
@@ -161,6 +188,9 @@ func GetHandshakedUTlsConn(opt TlsConnOpt) (*tls.UConn, error) {
 		if isTimeoutErr(err) {
 			return nil, ErrTlsHandshakeTimeout
 		}
+		if e, ok := errors.AsType[*tls.ECHRejectionError](err); ok {
+			return nil, &EchRejectionError{RetryConfigList: e.RetryConfigList}
+		}
 		if handledErr, ok := tryHandleErr(err); ok {
 			return nil, handledErr
 		}
@@ -182,73 +212,138 @@ func setUTlsAlpn(spec *tls.ClientHelloSpec, protos []string) {
 	spec.Extensions = append(spec.Extensions, &tls.ALPNExtension{AlpnProtocols: protos})
 }
 
-// TODO: Should this be in the http.go file?
-func TlsReadHttpResponse(ctx context.Context, tlsConn *tls.UConn, br *bufio.Reader) (*http.Response, error) {
-	done := make(chan struct{})
-	defer close(done)
-	defer tlsConn.SetReadDeadline(time.Time{})
+func isPqCurve(c tls.CurveID) bool {
+	return c == tls.X25519MLKEM768 || c == tls.X25519Kyber768Draft00
+}
 
-	go func() {
-		select {
-		case <-ctx.Done():
-			_ = tlsConn.SetReadDeadline(time.Now())
-		case <-done:
+// Makes sure that X25519MLKEM768 is offered first in both supported groups and key shares.
+func setUTlsPqKeyShare(spec *tls.ClientHelloSpec) {
+	for i := range spec.Extensions {
+		switch ext := spec.Extensions[i].(type) {
+		case *tls.SupportedCurvesExtension:
+			if !slices.Contains(ext.Curves, tls.X25519MLKEM768) {
+				ext.Curves = slices.Insert(ext.Curves, greaseOffset(ext.Curves), tls.X25519MLKEM768)
+			}
+		case *tls.KeyShareExtension:
+			has := slices.ContainsFunc(ext.KeyShares, func(ks tls.KeyShare) bool { return ks.Group == tls.X25519MLKEM768 })
+			if !has {
+				offset := greaseOffset(keyShareGroups(ext.KeyShares))
+				ext.KeyShares = slices.Insert(ext.KeyShares, offset, tls.KeyShare{Group: tls.X25519MLKEM768})
+			}
 		}
-	}()
+	}
+}
 
-	resp, err := http.ReadResponse(br, nil)
-	if err != nil {
-		if isTimeoutErr(err) {
-			return nil, ErrTcpReadTimeout
-		}
-		if handledErr, ok := tryHandleErr(err); ok {
-			return nil, handledErr
+// Removes post-quantum groups from both supported groups and key shares.
+func removeUTlsPqKeyShare(spec *tls.ClientHelloSpec) {
+	for i := range spec.Extensions {
+		switch ext := spec.Extensions[i].(type) {
+		case *tls.SupportedCurvesExtension:
+			ext.Curves = slices.DeleteFunc(ext.Curves, isPqCurve)
+		case *tls.KeyShareExtension:
+			ext.KeyShares = slices.DeleteFunc(ext.KeyShares, func(ks tls.KeyShare) bool { return isPqCurve(ks.Group) })
 		}
-		log.Println("TlsReadHttpResponse", err)
-		return nil, ErrInternal
 	}
-	return resp, nil
+}
+
+func keyShareGroups(kss []tls.KeyShare) []tls.CurveID {
+	groups := make([]tls.CurveID, len(kss))
+	for i, ks := range kss {
+		groups[i] = ks.Group
+	}
+	return groups
+}
+
+// Returns the position right after the leading GREASE value (if any).
+func greaseOffset(curves []tls.CurveID) int {
+	if len(curves) > 0 && curves[0] == tls.GREASE_PLACEHOLDER {
+		return 1
+	}
+	return 0
+}
+
+func TlsReadHttpResponse(ctx context.Context, tlsConn *tls.UConn, br *bufio.Reader) (*http.Response, error) {
+	return connReadHttpResponse(ctx, tlsConn, br, "TlsReadHttpResponse")
+}
+
+// Reads (and discards) the http response body; returns the number of bytes read.
+func TlsReadHttpBody(ctx context.Context, tlsConn *tls.UConn, body io.Reader) (int64, error) {
+	return connReadHttpBody(ctx, tlsConn, body, "TlsReadHttpBody")
 }
 
 func TlsWriteHttpRequest(ctx context.Context, tlsConn *tls.UConn, req *http.Request) (int64, error) {
+	return connWriteHttpRequest(ctx, tlsConn, req, "TlsHttpRequest")
+}
+
+// Writes b into tlsConn in chunks of chunkSize bytes with a delay between them.
+// Each chunk is sent as a separate tls record (and, as a rule, as a separate tcp segment).
+func TlsWriteChunked(ctx context.Context, tlsConn *tls.UConn, b []byte, chunkSize int, delay time.Duration) (int64, error) {
+	defer ctxDeadline(ctx, tlsConn.SetWriteDeadline)()
+
+	if chunkSize <= 0 {
+		chunkSize = len(b)
+	}
+
+	var n int64
+	for i := 0; i < len(b); i += chunkSize {
+		if i > 0 && delay > 0 {
+			select {
+			case <-ctx.Done():
+				return n, ErrTcpWriteTimeout
+			case <-time.After(delay):
+			}
+		}
+
+		chunk := b[i:min(i+chunkSize, len(b))]
+		if _, err := tlsConn.Write(chunk); err != nil {
+			if isTimeoutErr(err) {
+				return n, ErrTcpWriteTimeout
+			}
+			if handledErr, ok := tryHandleErr(err); ok {
+				return n, handledErr
+			}
+			log.Println("TlsWriteChunked", err)
+			return n, ErrInternal
+		}
+		n += int64(len(chunk))
+	}
+	return n, nil
+}
+
+// Interrupts blocking i/o (via setDeadline) when ctx is done.
+// The returned func must be called after i/o: it waits for the watcher to exit and resets the deadline,
+// so the connection can be safely reused (e.g. keep-alive).
+func ctxDeadline(ctx context.Context, setDeadline func(time.Time) error) func() {
 	done := make(chan struct{})
-	defer close(done)
-	defer tlsConn.SetWriteDeadline(time.Time{})
+	exited := make(chan struct{})
 
 	go func() {
+		defer close(exited)
 		select {
 		case <-ctx.Done():
-			_ = tlsConn.SetWriteDeadline(time.Now())
+			_ = setDeadline(time.Now())
 		case <-done:
 		}
 	}()
 
-	var writeBuf bytes.Buffer
-	if err := req.Write(&writeBuf); err != nil {
-		return 0, err
+	return func() {
+		close(done)
+		<-exited
+		_ = setDeadline(time.Time{})
 	}
-	n := int64(writeBuf.Len())
-
-	if _, err := tlsConn.Write(writeBuf.Bytes()); err != nil {
-		if isTimeoutErr(err) {
-			return 0, ErrTcpWriteTimeout
-		}
-		if handledErr, ok := tryHandleErr(err); ok {
-			return 0, handledErr
-		}
-		log.Println("TlsHttpRequest", err)
-		return 0, ErrInternal
-	}
-	return n, nil
 }
 
 func IsInetutilErr(err error) bool {
+	if errors.Is(err, ErrTlsEchRejected) {
+		return true
+	}
 	switch err {
-	case ErrTcpConnReset, ErrTcpConnTimeout, ErrTcpWriteTimeout,
-		ErrTcpReadTimeout, ErrTlsCertificateInvalid, ErrTlsHandshakeTimeout,
+	case ErrTcpConnReset, ErrTcpConnTimeout, ErrTcpConnRefused, ErrTcpWriteTimeout,
+		ErrTcpReadTimeout, ErrTcpConnClosed, ErrTlsCertificateInvalid, ErrTlsHandshakeTimeout,
 		ErrTlsHandshakeFail, ErrTlsInternal, ErrTlsBadRecordMac,
 		ErrTlsInvalidKeyShare, ErrTlsWriteBrokenPipe, ErrHttpMalformedResponse,
-		ErrInternal:
+		ErrQuicHandshakeTimeout, ErrQuicHandshakeFail, ErrQuicVersionNegotiation,
+		ErrQuicStatelessReset, ErrQuicStreamTimeout, ErrQuicStreamClosed, ErrUdpReadTimeout, ErrUdpPortUnreachable, ErrStunMalformed, ErrInternal:
 		return true
 	default:
 		return false
@@ -296,6 +391,9 @@ func tryHandleErr(err error) (error, bool) {
 	if strings.Contains(err.Error(), "connection reset") {
 		return ErrTcpConnReset, true
 	}
+	if strings.Contains(err.Error(), "connection refused") {
+		return ErrTcpConnRefused, true
+	}
 	if strings.Contains(err.Error(), "write: broken pipe") {
 		return ErrTlsWriteBrokenPipe, true
 	}
@@ -303,7 +401,7 @@ func tryHandleErr(err error) (error, bool) {
 	return err, false
 }
 
-// Returns default tls dialer local address for inetutil package, considering network interface options in config.
+// Returns default tls (more precisely, tcp) dialer local address for inetutil package, considering network interface options in config.
 func tlsDefaultDialerLocalAddr() net.Addr {
 	tlsMu.Lock()
 	defer tlsMu.Unlock()
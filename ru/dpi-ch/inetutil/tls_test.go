@@ -0,0 +1,92 @@
+package inetutil
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+// Accepts one connection and returns the length of the first tls record (i.e. ClientHello).
+func tlsTestHelloLen(t *testing.T) (netip.AddrPort, <-chan int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan int, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		hdr := make([]byte, 5)
+		if _, err := io.ReadFull(conn, hdr); err != nil {
+			ch <- 0
+			return
+		}
+		ch <- int(binary.BigEndian.Uint16(hdr[3:]))
+	}()
+
+	return ln.Addr().(*net.TCPAddr).AddrPort(), ch
+}
+
+func TestTlsKeyShareHelloLen(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	helloLen := func(keyShare KeyShare) int {
+		addr, ch := tlsTestHelloLen(t)
+		GetHandshakedUTlsConn(TlsConnOpt{
+			Ip:                  addr.Addr(),
+			Port:                int(addr.Port()),
+			Sni:                 "example.com",
+			TlsHandshakeTimeout: 300 * time.Millisecond,
+			KeyShare:            keyShare,
+		})
+		return <-ch
+	}
+
+	pq, classic := helloLen(KeySharePq), helloLen(KeyShareClassic)
+	// X25519MLKEM768 key share alone is 1216 bytes
+	if pq <= 1216 || classic >= 1216 {
+		t.Fatalf("got pq hello %d bytes, classic hello %d bytes", pq, classic)
+	}
+}
+
+func TestTlsKeyShareHandshake(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.TLS = &tls.Config{MinVersion: tls.VersionTLS13}
+	srv.StartTLS()
+	defer srv.Close()
+	addr := netip.MustParseAddrPort(srv.Listener.Addr().String())
+
+	for _, keyShare := range []KeyShare{KeyShareDefault, KeySharePq, KeyShareClassic} {
+		conn, err := GetHandshakedUTlsConn(TlsConnOpt{
+			Ip:                  addr.Addr(),
+			Port:                int(addr.Port()),
+			Sni:                 "example.com",
+			TlsHandshakeTimeout: 3 * time.Second,
+			KeyShare:            keyShare,
+		})
+		if err != nil {
+			t.Fatalf("key share %d: got %v, want nil", keyShare, err)
+		}
+		conn.Close()
+	}
+}
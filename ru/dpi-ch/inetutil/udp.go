@@ -0,0 +1,78 @@
+package inetutil
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+var (
+	ErrUdpReadTimeout     = errors.New("udp: read timeout")
+	ErrUdpPortUnreachable = errors.New("udp: port unreachable (icmp)")
+)
+
+type UdpConnOpt struct {
+	Ip   netip.Addr
+	Port int
+}
+
+// Returns unconnected udp socket (e.g. to talk to several peers from the same local port);
+// the network interface from config is considered.
+func GetUdpListener() (*net.UDPConn, error) {
+	conn, err := net.ListenUDP("udp", quicDefaultLocalAddr())
+	if err != nil {
+		log.Println("getUdpListener/ListenUDP", err)
+		return nil, ErrInternal
+	}
+	return conn, nil
+}
+
+// Returns connected udp socket (so icmp errors are reported on read); the network interface from config is considered.
+func GetUdpConn(opt UdpConnOpt) (*net.UDPConn, error) {
+	addr := &net.UDPAddr{IP: net.IP(opt.Ip.AsSlice()), Port: opt.Port}
+	conn, err := net.DialUDP("udp", quicDefaultLocalAddr(), addr)
+	if err != nil {
+		log.Println("getUdpConn/DialUDP", err)
+		return nil, ErrInternal
+	}
+	return conn, nil
+}
+
+// Writes packets (each one is a separate datagram) into conn and waits for the first datagram in response;
+// returns its size. An icmp error received instead (e.g. port unreachable) is returned as ErrUdpPortUnreachable.
+func UdpWriteRead(ctx context.Context, conn *net.UDPConn, packets ...[]byte) (int, error) {
+	resp, err := UdpWriteReadPacket(ctx, conn, packets...)
+	return len(resp), err
+}
+
+// Like UdpWriteRead, but returns the datagram itself.
+func UdpWriteReadPacket(ctx context.Context, conn *net.UDPConn, packets ...[]byte) ([]byte, error) {
+	defer ctxDeadline(ctx, conn.SetDeadline)()
+
+	for _, p := range packets {
+		if _, err := conn.Write(p); err != nil {
+			return nil, udpHandleErr(err, "UdpWriteRead/Write")
+		}
+	}
+
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, udpHandleErr(err, "UdpWriteRead/Read")
+	}
+	return buf[:n], nil
+}
+
+func udpHandleErr(err error, logPrefix string) error {
+	if isTimeoutErr(err) {
+		return ErrUdpReadTimeout
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrUdpPortUnreachable
+	}
+	log.Println(logPrefix, err)
+	return ErrInternal
+}
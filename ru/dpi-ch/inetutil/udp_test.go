@@ -0,0 +1,69 @@
+package inetutil
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/config"
+)
+
+func TestUdpWriteRead(t *testing.T) {
+	if err := config.Load(config.CfgDefPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// echoes datagrams that start with "ping", others are dropped
+	responder, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer responder.Close()
+	go func() {
+		b := make([]byte, 1500)
+		for {
+			n, addr, err := responder.ReadFromUDP(b)
+			if err != nil {
+				return
+			}
+			if n >= 4 && string(b[:4]) == "ping" {
+				responder.WriteToUDP(b[:n], addr)
+			}
+		}
+	}()
+
+	// nobody listens on this port
+	closed, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.LocalAddr().(*net.UDPAddr).AddrPort()
+	closed.Close()
+
+	addr := responder.LocalAddr().(*net.UDPAddr).AddrPort()
+	tests := map[string]struct {
+		addr    netip.AddrPort
+		packets [][]byte
+		want    error
+	}{
+		"reply":       {addr, [][]byte{[]byte("junk"), []byte("ping")}, nil},
+		"silence":     {addr, [][]byte{[]byte("junk")}, ErrUdpReadTimeout},
+		"unreachable": {closedAddr, [][]byte{[]byte("ping")}, ErrUdpPortUnreachable},
+	}
+	for name, tt := range tests {
+		conn, err := GetUdpConn(UdpConnOpt{Ip: tt.addr.Addr(), Port: int(tt.addr.Port())})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		_, err = UdpWriteRead(ctx, conn, tt.packets...)
+		cancel()
+		conn.Close()
+		if err != tt.want {
+			t.Fatalf("%s: got %v, want %v", name, err, tt.want)
+		}
+	}
+}
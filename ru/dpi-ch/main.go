@@ -30,6 +30,13 @@ func main() {
 	cfgPath := flag.String("cfg", config.CfgDefPath, ".yaml config path")
 	flag.Parse()
 
+	var diagnoseHost string
+	if flag.Arg(0) == "diagnose" {
+		if diagnoseHost = flag.Arg(1); diagnoseHost == "" {
+			log.Fatalf("usage: dpi-ch [flags] diagnose <host>")
+		}
+	}
+
 	if err := config.Load(*cfgPath); err != nil {
 		log.Fatalf("config load err: %v", err)
 	}
@@ -56,6 +63,9 @@ func main() {
 	if *all {
 		config.RunAllChecksImmediately()
 	}
+	if diagnoseHost != "" {
+		config.DiagnoseImmediately(diagnoseHost)
+	}
 
 	switch *ui {
 	case "t":
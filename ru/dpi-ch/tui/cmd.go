@@ -21,6 +21,13 @@ func (rm rootModel) Init() tea.Cmd {
 		}
 	}
 
+	if cfg.Checkers.Diagnose.Host != "" {
+		return func() tea.Msg {
+			inetlookup.Default()
+			return diagnoseInitMsg{Host: cfg.Checkers.Diagnose.Host}
+		}
+	}
+
 	selfTtu, _ := updater.TimeToUpdate(cfg.Updater.SelfTsFile)
 	inetlookupTtu, _ := updater.TimeToUpdate(cfg.Updater.InetlookupTsFile)
 	if cfg.Updater.ForceUpdate || cfg.Updater.ForceInetlookupUpdate || (cfg.Updater.Enabled && (selfTtu || inetlookupTtu)) {
@@ -86,6 +93,9 @@ func dnsProducerStartCmd(ctx context.Context) tea.Cmd {
 				leak:          checkers.DnsLeakGochan(ctx),
 				providerPlain: checkers.DnsPlainGochan(ctx),
 				providerDoh:   checkers.DnsDohGochan(ctx),
+				providerDot:   checkers.DnsDotGochan(ctx),
+				providerDoq:   checkers.DnsDoqGochan(ctx),
+				providerDoh3:  checkers.DnsDoh3Gochan(ctx),
 				progress:      make(chan string, 16),
 			},
 		}
@@ -94,7 +104,8 @@ func dnsProducerStartCmd(ctx context.Context) tea.Cmd {
 
 func dnsConsumerCmd(out dnsChannelModel) tea.Cmd {
 	return func() tea.Msg {
-		for out.providerPlain != nil || out.providerDoh != nil || out.leak != nil {
+		for out.providerPlain != nil || out.providerDoh != nil || out.providerDot != nil ||
+			out.providerDoq != nil || out.providerDoh3 != nil || out.leak != nil {
 			select {
 			case v, ok := <-out.providerPlain:
 				if !ok {
@@ -108,6 +119,24 @@ func dnsConsumerCmd(out dnsChannelModel) tea.Cmd {
 					continue
 				}
 				return dnsProviderDohMsg(v)
+			case v, ok := <-out.providerDot:
+				if !ok {
+					out.providerDot = nil
+					continue
+				}
+				return dnsProviderDotMsg(v)
+			case v, ok := <-out.providerDoq:
+				if !ok {
+					out.providerDoq = nil
+					continue
+				}
+				return dnsProviderDoqMsg(v)
+			case v, ok := <-out.providerDoh3:
+				if !ok {
+					out.providerDoh3 = nil
+					continue
+				}
+				return dnsProviderDoh3Msg(v)
 			case v, ok := <-out.leak:
 				if !ok {
 					out.leak = nil
@@ -122,6 +151,305 @@ func dnsConsumerCmd(out dnsChannelModel) tea.Cmd {
 	}
 }
 
+func quicProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return quicProducerStartedMsg{out: checkers.QuicGochanRunner(ctx)}
+	}
+}
+
+func quicConsumerCmd(out checkers.QuicGochanRunnerOut) tea.Cmd {
+	return func() tea.Msg {
+		for out.Out != nil || out.Progress != nil {
+			select {
+			case v, ok := <-out.Out:
+				if !ok {
+					out.Out = nil
+					continue
+				}
+				return quicItemMsg(v)
+			case v, ok := <-out.Progress:
+				if !ok {
+					out.Progress = nil
+					continue
+				}
+				return quicProgressMsg(v)
+			}
+		}
+
+		return quicProducerDoneMsg{}
+	}
+}
+
+func fingerprintProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return fingerprintProducerStartedMsg{out: checkers.FingerprintGochanRunner(ctx)}
+	}
+}
+
+func fingerprintConsumerCmd(out checkers.FingerprintGochanRunnerOut) tea.Cmd {
+	return func() tea.Msg {
+		for out.Out != nil || out.Progress != nil {
+			select {
+			case v, ok := <-out.Out:
+				if !ok {
+					out.Out = nil
+					continue
+				}
+				return fingerprintItemMsg(v)
+			case v, ok := <-out.Progress:
+				if !ok {
+					out.Progress = nil
+					continue
+				}
+				return fingerprintProgressMsg(v)
+			}
+		}
+
+		return fingerprintProducerDoneMsg{}
+	}
+}
+
+func bypassProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return bypassProducerStartedMsg{out: checkers.BypassGochanRunner(ctx)}
+	}
+}
+
+func bypassConsumerCmd(out checkers.BypassGochanRunnerOut) tea.Cmd {
+	return func() tea.Msg {
+		for out.Out != nil || out.Progress != nil {
+			select {
+			case v, ok := <-out.Out:
+				if !ok {
+					out.Out = nil
+					continue
+				}
+				return bypassItemMsg(v)
+			case v, ok := <-out.Progress:
+				if !ok {
+					out.Progress = nil
+					continue
+				}
+				return bypassProgressMsg(v)
+			}
+		}
+
+		return bypassProducerDoneMsg{}
+	}
+}
+
+func echProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return echProducerStartedMsg{out: checkers.EchGochanRunner(ctx)}
+	}
+}
+
+func echConsumerCmd(out checkers.EchGochanRunnerOut) tea.Cmd {
+	return func() tea.Msg {
+		for out.Out != nil || out.Progress != nil {
+			select {
+			case v, ok := <-out.Out:
+				if !ok {
+					out.Out = nil
+					continue
+				}
+				return echItemMsg(v)
+			case v, ok := <-out.Progress:
+				if !ok {
+					out.Progress = nil
+					continue
+				}
+				return echProgressMsg(v)
+			}
+		}
+
+		return echProducerDoneMsg{}
+	}
+}
+
+func idleProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return idleProducerStartedMsg{out: checkers.IdleGochanRunner(ctx)}
+	}
+}
+
+func idleConsumerCmd(out checkers.IdleGochanRunnerOut) tea.Cmd {
+	return func() tea.Msg {
+		for out.Out != nil || out.Progress != nil {
+			select {
+			case v, ok := <-out.Out:
+				if !ok {
+					out.Out = nil
+					continue
+				}
+				return idleItemMsg(v)
+			case v, ok := <-out.Progress:
+				if !ok {
+					out.Progress = nil
+					continue
+				}
+				return idleProgressMsg(v)
+			}
+		}
+
+		return idleProducerDoneMsg{}
+	}
+}
+
+func portscanProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return portscanProducerStartedMsg{out: checkers.PortscanGochanRunner(ctx)}
+	}
+}
+
+func portscanConsumerCmd(out checkers.PortscanGochanRunnerOut) tea.Cmd {
+	return func() tea.Msg {
+		for out.Out != nil || out.Progress != nil {
+			select {
+			case v, ok := <-out.Out:
+				if !ok {
+					out.Out = nil
+					continue
+				}
+				return portscanItemMsg(v)
+			case v, ok := <-out.Progress:
+				if !ok {
+					out.Progress = nil
+					continue
+				}
+				return portscanProgressMsg(v)
+			}
+		}
+
+		return portscanProducerDoneMsg{}
+	}
+}
+
+func dnsinjectProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return dnsinjectProducerStartedMsg{out: checkers.DnsinjectGochanRunner(ctx)}
+	}
+}
+
+func dnsinjectConsumerCmd(out checkers.DnsinjectGochanRunnerOut) tea.Cmd {
+	return func() tea.Msg {
+		for out.Out != nil || out.Progress != nil {
+			select {
+			case v, ok := <-out.Out:
+				if !ok {
+					out.Out = nil
+					continue
+				}
+				return dnsinjectItemMsg(v)
+			case v, ok := <-out.Progress:
+				if !ok {
+					out.Progress = nil
+					continue
+				}
+				return dnsinjectProgressMsg(v)
+			}
+		}
+
+		return dnsinjectProducerDoneMsg{}
+	}
+}
+
+func httphostProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return httphostProducerStartedMsg{out: checkers.HttpHostGochan(ctx)}
+	}
+}
+
+func httphostConsumerCmd(out <-chan checkers.HttpHostResult) tea.Cmd {
+	return func() tea.Msg {
+		v, ok := <-out
+		if !ok {
+			return httphostProducerDoneMsg{}
+		}
+		return httphostItemMsg(v)
+	}
+}
+
+func tunnelProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return tunnelProducerStartedMsg{out: checkers.TunnelGochan(ctx)}
+	}
+}
+
+func tunnelConsumerCmd(out <-chan checkers.TunnelResult) tea.Cmd {
+	return func() tea.Msg {
+		v, ok := <-out
+		if !ok {
+			return tunnelProducerDoneMsg{}
+		}
+		return tunnelItemMsg(v)
+	}
+}
+
+func udpvpnProducerStartCmd(ctx context.Context, targets []config.TunnelTarget) tea.Cmd {
+	return func() tea.Msg {
+		return udpvpnProducerStartedMsg{out: checkers.UdpvpnGochan(ctx, targets)}
+	}
+}
+
+func udpvpnConsumerCmd(out <-chan checkers.UdpvpnResult) tea.Cmd {
+	return func() tea.Msg {
+		v, ok := <-out
+		if !ok {
+			return udpvpnProducerDoneMsg{}
+		}
+		return udpvpnItemMsg(v)
+	}
+}
+
+func diagnoseProducerStartCmd(ctx context.Context, host string) tea.Cmd {
+	return func() tea.Msg {
+		return diagnoseProducerStartedMsg{out: checkers.DiagnoseGochan(ctx, host)}
+	}
+}
+
+func diagnoseConsumerCmd(out <-chan checkers.DiagnoseStep) tea.Cmd {
+	return func() tea.Msg {
+		v, ok := <-out
+		if !ok {
+			return diagnoseProducerDoneMsg{}
+		}
+		return diagnoseStepMsg(v)
+	}
+}
+
+func stunProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return stunProducerStartedMsg{out: checkers.StunGochan(ctx)}
+	}
+}
+
+func stunConsumerCmd(out <-chan checkers.StunResult) tea.Cmd {
+	return func() tea.Msg {
+		v, ok := <-out
+		if !ok {
+			return stunProducerDoneMsg{}
+		}
+		return stunItemMsg(v)
+	}
+}
+
+func compressionProducerStartCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return compressionProducerStartedMsg{out: checkers.CompressionGochan(ctx)}
+	}
+}
+
+func compressionConsumerCmd(out <-chan checkers.CompressionResult) tea.Cmd {
+	return func() tea.Msg {
+		v, ok := <-out
+		if !ok {
+			return compressionProducerDoneMsg{}
+		}
+		return compressionItemMsg(v)
+	}
+}
+
 func allProducerStartCmd(ctx context.Context) tea.Cmd {
 	return func() tea.Msg {
 		return allProducerStartedMsg{
@@ -9,9 +9,12 @@ import (
 	"net"
 	"os"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/checkers"
 	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/inetutil"
+	"github.com/quic-go/quic-go"
 	"golang.org/x/net/publicsuffix"
 
 	"charm.land/bubbles/v2/key"
@@ -44,14 +47,28 @@ func dnsPrettyProviderVerdict(err error) string {
 		return "❗️nxdomain spoofing"
 	case checkers.ErrDnsResolveSpoofing:
 		return "❗️response spoofing"
+	case checkers.ErrDnsUdpInjection:
+		return "❗️udp injection"
 	case checkers.ErrDnsDohBootstrapSpoofing:
 		return "❗️bootstrap spoofing"
 	case checkers.ErrDnsDohBootstrapEmpty:
 		return "⚠️ empty bootstrap"
-	case checkers.ErrDnsDohInsecure, inetutil.ErrTlsCertificateInvalid:
+	case checkers.ErrDnsDohInsecure, checkers.ErrDnsDoh3Insecure, inetutil.ErrTlsCertificateInvalid:
 		return "❗️invalid https certificate"
+	case checkers.ErrDnsDotInsecure, checkers.ErrDnsDoqInsecure:
+		return "❗️invalid tls certificate"
 	case checkers.ErrDnsDohNon2xxResp:
 		return "⚠️ non-2xx response"
+	case checkers.ErrDnsServfail:
+		return "⚠️ servfail"
+	case checkers.ErrDnsRefused:
+		return "⚠️ refused"
+	case checkers.ErrDnsRcode:
+		return "⚠️ unexpected rcode"
+	case checkers.ErrDnsEmptyAnswer:
+		return "⚠️ empty answer"
+	case checkers.ErrDnsMalformed:
+		return "⚠️ malformed response"
 	case checkers.ErrDnsSkip:
 		return "⏩ skip"
 	default:
@@ -60,6 +77,402 @@ func dnsPrettyProviderVerdict(err error) string {
 	}
 }
 
+func compressionPrettyVerdict(err error) string {
+	switch err {
+	case nil:
+		return "✅ ok"
+	case checkers.ErrCompressionNotSupported:
+		return "⚠️ not supported by host"
+	case checkers.ErrCompressionEofBeforeMin:
+		return "❗️eof before min"
+	case checkers.ErrCompressionTimeout:
+		return "❗️timeout"
+	case checkers.ErrCompressionConnErr:
+		return "🔴 connection error"
+	default:
+		return "⚠️ internal error"
+	}
+}
+
+func quicPrettyHandshake(err error) string {
+	switch err {
+	case nil:
+		return "🟢 yes"
+	case inetutil.ErrQuicHandshakeTimeout:
+		return "🔴 timeout"
+	case inetutil.ErrQuicVersionNegotiation:
+		return "⚠️ version negotiation"
+	}
+
+	return fmt.Sprintf("🔴 %s", err)
+}
+
+func quicPrettyVersion(v quic.Version) string {
+	switch v {
+	case quic.Version1:
+		return "v1"
+	case quic.Version2:
+		return "v2"
+	default:
+		return " — "
+	}
+}
+
+func quicPrettyVerdict(err error) string {
+	switch err {
+	case nil:
+		return "✅ ok"
+	case checkers.ErrQuicBlocked:
+		return "❗️quic blocked"
+	case checkers.ErrQuicTcpBlocked:
+		return "❗️tcp/tls blocked"
+	case checkers.ErrQuicNotSupported:
+		return "⚠️ not supported by host"
+	case checkers.ErrQuicUnavailable:
+		return "🔴 host unavailable"
+	default:
+		return "⚠️ internal error"
+	}
+}
+
+func fingerprintPrettyVerdict(err error) string {
+	switch err {
+	case nil:
+		return "🟢"
+	case checkers.ErrFingerprintThrottled:
+		return "🐢"
+	case checkers.ErrFingerprintDropped:
+		return "🔴"
+	case checkers.ErrFingerprintUnavailable:
+		return "⛔"
+	default:
+		return "⚠️"
+	}
+}
+
+func bypassPrettyBaseline(err error) string {
+	if err == nil {
+		return "✅ not blocked"
+	}
+	return fmt.Sprintf("❗️%s", err)
+}
+
+func bypassPrettyStrategy(err error) string {
+	switch err {
+	case nil:
+		return "🟢 works"
+	case checkers.ErrWebhostSkip:
+		return "⚠️ skip"
+	case checkers.ErrBypassUnknownStrategy:
+		return "⚠️ unknown"
+	default:
+		return fmt.Sprintf("🔴 %s", err)
+	}
+}
+
+func echPrettyHandshake(err error) string {
+	switch {
+	case err == nil:
+		return "🟢 accepted"
+	case err == checkers.ErrEchRetry:
+		return "🟢 accepted after retry"
+	case errors.Is(err, inetutil.ErrTlsEchRejected):
+		return "🔴 rejected"
+	case err == inetutil.ErrTlsHandshakeTimeout:
+		return "🔴 timeout"
+	case err == checkers.ErrEchNoConfig:
+		return "⚠️ no ECHConfigList"
+	case err == checkers.ErrWebhostSkip:
+		return "⚠️ skip"
+	}
+
+	return fmt.Sprintf("🔴 %s", err)
+}
+
+func echPrettyVerdict(err error) string {
+	switch err {
+	case nil:
+		return "✅ ok"
+	case checkers.ErrEchBlocked:
+		return "❗️ech blocked"
+	case checkers.ErrEchDowngraded:
+		return "❗️ech downgraded"
+	case checkers.ErrEchUnavailable:
+		return "🔴 host unavailable"
+	case checkers.ErrEchNoConfig:
+		return "⚠️ no ECHConfigList"
+	case checkers.ErrWebhostSkip:
+		return "⚠️ skip"
+	default:
+		return "⚠️ internal error"
+	}
+}
+
+// Returns the longest interval that survived with the keepalive, and the reason of the first kill (if any).
+func idlePrettyLongest(res checkers.IdleSingleResult, keepalive string) string {
+	var items []checkers.IdleItem
+	for _, x := range res.Items {
+		if x.Keepalive == keepalive {
+			items = append(items, x)
+		}
+	}
+	if len(items) == 0 {
+		return "⚠️ skip"
+	}
+
+	longest := res.Longest[keepalive]
+	var kill error
+	var killInterval time.Duration
+	for _, x := range items {
+		if x.Verdict != nil && (kill == nil || x.Interval < killInterval) {
+			kill, killInterval = x.Verdict, x.Interval
+		}
+	}
+
+	switch {
+	case kill == nil:
+		return fmt.Sprintf("🟢 %s", longest)
+	case kill == checkers.ErrWebhostSkip:
+		return "⚠️ skip"
+	case kill == checkers.ErrIdleUnavailable:
+		return "⚠️ host unavailable"
+	case kill == checkers.ErrIdleUnknownKeepalive:
+		return "⚠️ unknown keepalive"
+	case longest == 0:
+		return fmt.Sprintf("🔴 none (%s)", idlePrettyKill(kill))
+	default:
+		return fmt.Sprintf("🟡 %s (%s)", longest, idlePrettyKill(kill))
+	}
+}
+
+func idlePrettyKill(err error) string {
+	switch err {
+	case checkers.ErrIdleKilled:
+		return "silently"
+	case checkers.ErrIdleReset:
+		return "reset"
+	case checkers.ErrIdleServerClosed:
+		return "closed by server"
+	default:
+		return "internal error"
+	}
+}
+
+func diagnosePrettyMark(err error) string {
+	switch err {
+	case nil:
+		return "✅"
+	case checkers.ErrWebhostSkip:
+		return "⚠️ "
+	default:
+		return "❗️"
+	}
+}
+
+func diagnosePrettyErr(err error) string {
+	switch err {
+	case nil:
+		return "no interference"
+	case checkers.ErrWebhostSkip:
+		return "skip"
+	default:
+		return err.Error()
+	}
+}
+
+func portscanPrettyVerdict(err error) string {
+	switch err {
+	case nil:
+		return "🟢 open"
+	case checkers.ErrPortscanRefused:
+		return "🟡 refused"
+	case checkers.ErrPortscanFiltered:
+		return "🔴 filtered"
+	case checkers.ErrPortscanReset:
+		return "🔴 reset"
+	case checkers.ErrPortscanUnavailable:
+		return "⚠️ host unavailable"
+	case checkers.ErrWebhostSkip:
+		return "⚠️ skip"
+	default:
+		return "⚠️ internal error"
+	}
+}
+
+func dnsinjectPrettyVerdict(err error) string {
+	if inetutil.IsInetutilErr(err) {
+		return "⚠️ " + err.Error()
+	}
+	switch err {
+	case nil:
+		return "🟢 silent"
+	case checkers.ErrDnsinjectInjected:
+		return "🔴 injected"
+	case checkers.ErrDnsinjectNotSilent:
+		return "⚠️ ip is not silent"
+	case checkers.ErrDnsSkip:
+		return "⚠️ skip"
+	default:
+		return "⚠️ internal error"
+	}
+}
+
+// Injected A records with their ttls and orgs, e.g. "10.0.0.1 (Org; ttl 300)".
+func dnsinjectPrettyAnswers(item checkers.DnsinjectItem) string {
+	if item.Err == checkers.ErrDnsMalformed {
+		return "malformed response"
+	}
+	if item.Err != nil {
+		return ""
+	}
+	if len(item.Answers) == 0 {
+		return "rcode: " + item.Rcode.String()
+	}
+	out := []string{}
+	for _, a := range item.Answers {
+		out = append(out, fmt.Sprintf("%s (%s; ttl %d)", a.IpInfo.Ip, a.IpInfo.Org, a.Ttl))
+	}
+	return strings.Join(out, ", ")
+}
+
+func tunnelPrettyResult(err error) string {
+	switch err {
+	case nil:
+		return "answered"
+	case inetutil.ErrTcpConnReset:
+		return "reset"
+	case inetutil.ErrTcpReadTimeout:
+		return "silence"
+	case inetutil.ErrTcpConnClosed:
+		return "closed"
+	case inetutil.ErrTcpConnTimeout:
+		return "conn timeout"
+	case checkers.ErrTunnelUnknownProtocol:
+		return "unknown protocol"
+	case checkers.ErrWebhostSkip:
+		return "skip"
+	default:
+		return "conn error"
+	}
+}
+
+func tunnelPrettyProbe(p checkers.TunnelProbe) string {
+	switch p.Verdict {
+	case nil:
+		return "🟢 " + tunnelPrettyResult(p.Err)
+	case checkers.ErrTunnelReset, checkers.ErrTunnelBlackholed, checkers.ErrTunnelClosed:
+		return "🔴 " + tunnelPrettyResult(p.Err)
+	default:
+		return "⚠️ " + tunnelPrettyResult(p.Err)
+	}
+}
+
+func udpvpnPrettyResult(err error) string {
+	switch err {
+	case nil:
+		return "replied"
+	case inetutil.ErrUdpPortUnreachable:
+		return "icmp unreachable"
+	case inetutil.ErrUdpReadTimeout:
+		return "silence"
+	case checkers.ErrUdpvpnUnknownProtocol:
+		return "unknown protocol"
+	case checkers.ErrWebhostSkip:
+		return "skip"
+	default:
+		return "error"
+	}
+}
+
+func udpvpnPrettyProbe(p checkers.UdpvpnProbe) string {
+	switch p.Verdict {
+	case nil:
+		return "🟢 " + udpvpnPrettyResult(p.Err)
+	case checkers.ErrUdpvpnDropped:
+		return "🔴 " + udpvpnPrettyResult(p.Err)
+	case checkers.ErrUdpvpnNoReaction:
+		return "⚪️ " + udpvpnPrettyResult(p.Err)
+	default:
+		return "⚠️ " + udpvpnPrettyResult(p.Err)
+	}
+}
+
+func stunPrettyTransport(r checkers.StunTransportResult) string {
+	switch r.Err {
+	case nil:
+		mark := "🟢"
+		if r.Received < r.Sent {
+			mark = "🐢"
+		}
+		return fmt.Sprintf("%s %d ms / %d%%", mark, r.Rtt.Milliseconds(), r.Loss())
+	case inetutil.ErrUdpReadTimeout, inetutil.ErrTcpReadTimeout:
+		return "🔴 timeout"
+	case inetutil.ErrUdpPortUnreachable:
+		return "🔴 icmp unreachable"
+	case inetutil.ErrTcpConnTimeout:
+		return "🔴 conn timeout"
+	case inetutil.ErrTcpConnReset:
+		return "🔴 reset"
+	case inetutil.ErrTcpConnClosed:
+		return "🔴 closed"
+	default:
+		return "🔴 error"
+	}
+}
+
+func stunPrettyVerdict(err error) string {
+	switch err {
+	case nil:
+		return "✅ ok"
+	case checkers.ErrStunUdpBlocked:
+		return "❗️udp blocked"
+	case checkers.ErrStunUnavailable:
+		return "🔴 unavailable"
+	default:
+		return "⚠️ internal error"
+	}
+}
+
+// Returns nat hint with mapped address (and a note if the udp external ip differs from the one above).
+func whoamiPrettyNat(r checkers.WhoamiResult) string {
+	if r.Nat.Err != nil {
+		return "unknown (no stun servers answered)"
+	}
+
+	mapped := r.Nat.Mapped[0]
+	s := fmt.Sprintf("%s, mapped %s via %d stun servers", r.Nat.Hint, mapped, len(r.Nat.Mapped))
+	if mapped.Addr().String() != r.Ip {
+		s += " ⚠️ external ip differs"
+	}
+	return s
+}
+
+func httphostPrettyLookup(err error) string {
+	if err == nil {
+		return "✅ ok"
+	}
+	return "⚠️ lookup error"
+}
+
+func httphostPrettyProbe(p checkers.HttpHostProbe) string {
+	switch p.Verdict {
+	case nil:
+		return fmt.Sprintf("✅ %d", p.HttpStatus)
+	case checkers.ErrHttpHostReset:
+		return "❗️reset"
+	case checkers.ErrHttpHostTimeout:
+		return "❗️timeout"
+	case checkers.ErrHttpHostRedirect:
+		return fmt.Sprintf("❗️redirect %d → %s", p.HttpStatus, p.Location)
+	case checkers.ErrHttpHostStub:
+		return fmt.Sprintf("❗️stub page %d", p.HttpStatus)
+	case checkers.ErrHttpHostConnErr:
+		return "🔴 connection error"
+	default:
+		return "⚠️ internal error"
+	}
+}
+
 func webhostPrettyAlive(err error) string {
 	switch err {
 	case nil:
@@ -69,6 +482,9 @@ func webhostPrettyAlive(err error) string {
 	case inetutil.ErrTlsInvalidKeyShare, inetutil.ErrTlsBadRecordMac:
 		return fmt.Sprintf("⚠️ %s", err)
 	}
+	if blockpage, ok := errors.AsType[*checkers.WebhostBlockpageError](err); ok {
+		return "❗️stub page: " + blockpage.Isp
+	}
 
 	return fmt.Sprintf("🔴 %s", err)
 }
@@ -100,13 +516,109 @@ func webhostPrettyTcp1620(err error) string {
 		return "❗️detected"
 	case checkers.ErrWebhostSkip:
 		return "⚠️ skip"
-	case inetutil.ErrTlsWriteBrokenPipe:
+	case inetutil.ErrTlsWriteBrokenPipe, checkers.ErrWebhostNotEnoughRx:
 		return "⚠️ not supported by host"
 	default:
 		return fmt.Sprintf("⚠️ %s", err)
 	}
 }
 
+func webhostPrettyTcp1620Cutoff(cutoff int64) string {
+	if cutoff <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (≤%.1f KB)", float64(cutoff)/1024)
+}
+
+func webhostPrettySeries(series *checkers.WebhostThroughputSeries) string {
+	if series == nil {
+		return "⚠️ skip"
+	}
+	switch series.Verdict {
+	case nil:
+		return "✅ no"
+	case checkers.ErrWebhostSeriesFrozen:
+		return fmt.Sprintf("❗️frozen since %s", series.PlateauStart)
+	case checkers.ErrWebhostSeriesThrottled:
+		return fmt.Sprintf("❗️%.1f kb/s since %s", series.PlateauKbps, series.PlateauStart)
+	default:
+		return fmt.Sprintf("⚠️ %s", series.Verdict)
+	}
+}
+
+// Identifies a webhost table row (group and ip), since rows are re-sorted on every item.
+func webhostRowKey(row table.Row) string {
+	return row[0] + "/" + row[4]
+}
+
+var sparklineBars = []rune("▁▂▃▄▅▆▇█")
+
+// Draws per-step increments of cumulative samples as a sparkline ("·" is no data at all);
+// long series are squeezed into width (several steps per bar).
+func sparkline(samples []int64, width int) string {
+	if len(samples) == 0 {
+		return ""
+	}
+
+	step := max(1, (len(samples)+width-1)/width)
+	var bars []int64
+	var prev int64
+	for i := step - 1; i < len(samples)+step-1; i += step {
+		curr := samples[min(i, len(samples)-1)]
+		bars = append(bars, curr-prev)
+		prev = curr
+	}
+
+	top := slices.Max(bars)
+	var b strings.Builder
+	for _, x := range bars {
+		if x <= 0 {
+			b.WriteRune('·')
+			continue
+		}
+		b.WriteRune(sparklineBars[int(x*int64(len(sparklineBars)-1)/top)])
+	}
+	return b.String()
+}
+
+func webhostPrettyL4_25(err error) string {
+	switch err {
+	case nil:
+		return "✅ no"
+	case inetutil.ErrTcpWriteTimeout, inetutil.ErrTcpReadTimeout:
+		return "❗️detected"
+	case checkers.ErrWebhostSkip:
+		return "⚠️ skip"
+	default:
+		return fmt.Sprintf("⚠️ %s", err)
+	}
+}
+
+// e.g. "🟢original 🟢empty 🔴random 🟢cf.com"
+func webhostPrettySniMatrix(items []checkers.WebhostSniMatrixItem) string {
+	if items == nil {
+		return " — "
+	}
+
+	parts := []string{}
+	for _, x := range items {
+		name := x.Kind
+		if x.Kind == checkers.SniMatrixFallback {
+			name = x.Sni
+		}
+
+		switch x.Err {
+		case nil:
+			parts = append(parts, "🟢"+name)
+		case checkers.ErrWebhostSkip:
+			parts = append(parts, "⚠️"+name)
+		default:
+			parts = append(parts, "🔴"+name)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 func webhostPrettySanCn(t *checkers.WebhostTls) string {
 	if t == nil {
 		return "—"
@@ -141,6 +653,19 @@ func webhostPrettySanCn(t *checkers.WebhostTls) string {
 	return res
 }
 
+func webhostPrettyPqKeyShare(err error) string {
+	switch err {
+	case nil:
+		return "✅ no"
+	case checkers.ErrWebhostPqBlocked:
+		return "❗️detected"
+	case checkers.ErrWebhostSkip:
+		return "⚠️ skip"
+	default:
+		return fmt.Sprintf("⚠️ %s", err)
+	}
+}
+
 func webhostPrettySiberian(err error) string {
 	switch err {
 	case nil:
@@ -20,6 +20,19 @@ type rootModel struct {
 	cidrwhitelistModel cidrwhitelistModel
 	webhostModel       webhostModel
 	dnsModel           dnsModel
+	compressionModel   compressionModel
+	quicModel          quicModel
+	httphostModel      httphostModel
+	fingerprintModel   fingerprintModel
+	bypassModel        bypassModel
+	echModel           echModel
+	tunnelModel        tunnelModel
+	udpvpnModel        udpvpnModel
+	stunModel          stunModel
+	idleModel          idleModel
+	portscanModel      portscanModel
+	dnsinjectModel     dnsinjectModel
+	diagnoseModel      diagnoseModel
 	updaterModel       updaterModel
 }
 
@@ -55,6 +68,9 @@ type webhostModel struct {
 	progress    string
 	table       table.Model
 	farmTimeout bool
+	// throughput series by row (see webhostRowKey); the one under the cursor is drawn in the detail view
+	series map[string]*checkers.WebhostThroughputSeries
+	detail bool
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -62,15 +78,22 @@ type webhostModel struct {
 }
 
 type dnsChannelModel struct {
-	providerPlain <-chan checkers.DnsVerdict
+	providerPlain <-chan checkers.DnsPlainVerdict
 	providerDoh   <-chan checkers.DnsVerdict
+	providerDot   <-chan checkers.DnsVerdict
+	providerDoq   <-chan checkers.DnsVerdict
+	providerDoh3  <-chan checkers.DnsVerdict
 	leak          <-chan checkers.DnsLeakWithIpinfoOut
 	progress      chan string
 }
 
 type dnsVerdictModel struct {
-	plainVerdict error
-	dohVerdict   error
+	udpVerdict  error
+	tcpVerdict  error
+	dohVerdict  error
+	dotVerdict  error
+	doqVerdict  error
+	doh3Verdict error
 }
 
 type dnsModel struct {
@@ -89,6 +112,169 @@ type dnsModel struct {
 	cancel context.CancelFunc
 }
 
+type compressionModel struct {
+	inited   bool
+	fetching bool
+	spinner  spinner.Model
+	progress string
+	table    table.Model
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    <-chan checkers.CompressionResult
+}
+
+type quicModel struct {
+	inited      bool
+	fetching    bool
+	spinner     spinner.Model
+	progress    string
+	table       table.Model
+	farmTimeout bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    checkers.QuicGochanRunnerOut
+}
+
+type httphostModel struct {
+	inited   bool
+	fetching bool
+	spinner  spinner.Model
+	progress string
+	table    table.Model
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    <-chan checkers.HttpHostResult
+}
+
+type fingerprintModel struct {
+	inited      bool
+	fetching    bool
+	spinner     spinner.Model
+	progress    string
+	table       table.Model
+	farmTimeout bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    checkers.FingerprintGochanRunnerOut
+}
+
+type bypassModel struct {
+	inited      bool
+	fetching    bool
+	spinner     spinner.Model
+	progress    string
+	table       table.Model
+	farmTimeout bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    checkers.BypassGochanRunnerOut
+}
+
+type echModel struct {
+	inited      bool
+	fetching    bool
+	spinner     spinner.Model
+	progress    string
+	table       table.Model
+	farmTimeout bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    checkers.EchGochanRunnerOut
+}
+
+type idleModel struct {
+	inited      bool
+	fetching    bool
+	spinner     spinner.Model
+	progress    string
+	table       table.Model
+	farmTimeout bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    checkers.IdleGochanRunnerOut
+}
+
+type portscanModel struct {
+	inited      bool
+	fetching    bool
+	spinner     spinner.Model
+	progress    string
+	table       table.Model
+	farmTimeout bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    checkers.PortscanGochanRunnerOut
+}
+
+type dnsinjectModel struct {
+	inited      bool
+	fetching    bool
+	spinner     spinner.Model
+	progress    string
+	table       table.Model
+	farmTimeout bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    checkers.DnsinjectGochanRunnerOut
+}
+
+type tunnelModel struct {
+	inited   bool
+	fetching bool
+	spinner  spinner.Model
+	progress string
+	table    table.Model
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    <-chan checkers.TunnelResult
+}
+
+type udpvpnModel struct {
+	inited   bool
+	fetching bool
+	spinner  spinner.Model
+	progress string
+	table    table.Model
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    <-chan checkers.UdpvpnResult
+}
+
+type stunModel struct {
+	inited   bool
+	fetching bool
+	spinner  spinner.Model
+	progress string
+	table    table.Model
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    <-chan checkers.StunResult
+}
+
+type diagnoseModel struct {
+	inited   bool
+	fetching bool
+	spinner  spinner.Model
+	host     string
+	steps    []checkers.DiagnoseStep
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    <-chan checkers.DiagnoseStep
+}
+
 type updaterModel struct {
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -34,10 +34,115 @@ type dnsProducerStartedMsg struct {
 }
 type dnsProducerDoneMsg struct{}
 type dnsLeakMsg checkers.DnsLeakWithIpinfoOut
-type dnsProviderPlainMsg checkers.DnsVerdict
+type dnsProviderPlainMsg checkers.DnsPlainVerdict
 type dnsProviderDohMsg checkers.DnsVerdict
+type dnsProviderDotMsg checkers.DnsVerdict
+type dnsProviderDoqMsg checkers.DnsVerdict
+type dnsProviderDoh3Msg checkers.DnsVerdict
 type dnsProgressMsg string
 
+type compressionInitMsg struct{}
+type compressionProducerStartedMsg struct {
+	out <-chan checkers.CompressionResult
+}
+type compressionProducerDoneMsg struct{}
+type compressionItemMsg checkers.CompressionResult
+
+type quicInitMsg struct{}
+type quicProducerStartedMsg struct {
+	out checkers.QuicGochanRunnerOut
+}
+type quicProducerDoneMsg struct{}
+type quicItemMsg checkers.QuicGochanOut
+type quicProgressMsg string
+
+type httphostInitMsg struct{}
+type httphostProducerStartedMsg struct {
+	out <-chan checkers.HttpHostResult
+}
+type httphostProducerDoneMsg struct{}
+type httphostItemMsg checkers.HttpHostResult
+
+type fingerprintInitMsg struct{}
+type fingerprintProducerStartedMsg struct {
+	out checkers.FingerprintGochanRunnerOut
+}
+type fingerprintProducerDoneMsg struct{}
+type fingerprintItemMsg checkers.FingerprintGochanOut
+type fingerprintProgressMsg string
+
+type bypassInitMsg struct{}
+type bypassProducerStartedMsg struct {
+	out checkers.BypassGochanRunnerOut
+}
+type bypassProducerDoneMsg struct{}
+type bypassItemMsg checkers.BypassGochanOut
+type bypassProgressMsg string
+
+type echInitMsg struct{}
+type echProducerStartedMsg struct {
+	out checkers.EchGochanRunnerOut
+}
+type echProducerDoneMsg struct{}
+type echItemMsg checkers.EchGochanOut
+type echProgressMsg string
+
+type idleInitMsg struct{}
+type idleProducerStartedMsg struct {
+	out checkers.IdleGochanRunnerOut
+}
+type idleProducerDoneMsg struct{}
+type idleItemMsg checkers.IdleGochanOut
+type idleProgressMsg string
+
+type portscanInitMsg struct{}
+type portscanProducerStartedMsg struct {
+	out checkers.PortscanGochanRunnerOut
+}
+type portscanProducerDoneMsg struct{}
+type portscanItemMsg checkers.PortscanGochanOut
+type portscanProgressMsg string
+
+type dnsinjectInitMsg struct{}
+type dnsinjectProducerStartedMsg struct {
+	out checkers.DnsinjectGochanRunnerOut
+}
+type dnsinjectProducerDoneMsg struct{}
+type dnsinjectItemMsg checkers.DnsinjectGochanOut
+type dnsinjectProgressMsg string
+
+type tunnelInitMsg struct{}
+type tunnelProducerStartedMsg struct {
+	out <-chan checkers.TunnelResult
+}
+type tunnelProducerDoneMsg struct{}
+type tunnelItemMsg checkers.TunnelResult
+
+type udpvpnInitMsg struct {
+	Targets []config.TunnelTarget
+}
+type udpvpnProducerStartedMsg struct {
+	out <-chan checkers.UdpvpnResult
+}
+type udpvpnProducerDoneMsg struct{}
+type udpvpnItemMsg checkers.UdpvpnResult
+
+type diagnoseInitMsg struct {
+	Host string
+}
+type diagnoseProducerStartedMsg struct {
+	out <-chan checkers.DiagnoseStep
+}
+type diagnoseProducerDoneMsg struct{}
+type diagnoseStepMsg checkers.DiagnoseStep
+
+type stunInitMsg struct{}
+type stunProducerStartedMsg struct {
+	out <-chan checkers.StunResult
+}
+type stunProducerDoneMsg struct{}
+type stunItemMsg checkers.StunResult
+
 type allInitMsg struct{}
 type allProducerStartedMsg struct {
 	out <-chan checkers.FullCheckProgress
@@ -18,6 +18,19 @@ const (
 	cidrwhitelistTab
 	webhostTab
 	dnsTab
+	compressionTab
+	quicTab
+	httphostTab
+	fingerprintTab
+	bypassTab
+	echTab
+	tunnelTab
+	udpvpnTab
+	stunTab
+	idleTab
+	portscanTab
+	dnsinjectTab
+	diagnoseTab
 	updaterTab
 )
 
@@ -52,6 +65,7 @@ func NewRouter() *router {
 
 func NewMenu() *MenuState {
 	webhostCfg := config.Get().Checkers.Webhost
+	udpvpnCfg := config.Get().Checkers.Udpvpn
 	m := &MenuState{items: []MenuItem{}}
 
 	m.Add("ALL", "long exec warn: run all checks specified in the config", allTab, true, allInitMsg{})
@@ -65,6 +79,29 @@ func NewMenu() *MenuState {
 	}
 
 	m.Add("DNS", "checks if a censor is spoofing dns responses, hijacking servers, DoH blocking, etc", dnsTab, true, dnsInitMsg{})
+	m.Add("HTTP compression", "checks if a censor cuts off compressed (gzip, deflate, br, zstd) http responses",
+		compressionTab, true, compressionInitMsg{})
+	m.Add("QUIC / HTTP/3", "checks if a censor blocks quic (udp) separately from tcp/tls", quicTab, true, quicInitMsg{})
+	m.Add("ECH", "checks if a censor drops or downgrades tls handshakes with encrypted client hello", echTab, true, echInitMsg{})
+	m.Add("Tunnels", "checks if a censor blocks vpn/proxy protocols (ssh, openvpn, shadowsocks, reality) by the first flight", tunnelTab, true, tunnelInitMsg{})
+
+	// udpvpn checker is split into sections (separate tabs) as well
+	for _, x := range udpvpnCfg.Sections {
+		m.Add(x.Name, x.Desc, udpvpnTab, x.Default, udpvpnInitMsg{Targets: x.Targets})
+	}
+
+	m.Add("STUN / WebRTC", "checks if a censor blocks voice/video calls: stun over udp and tcp (rtt, loss, mapped address)", stunTab, true, stunInitMsg{})
+	m.Add("Plain HTTP Host", "checks if a censor blocks cleartext http (port 80) by the Host header", httphostTab, true, httphostInitMsg{})
+	m.Add("TLS fingerprints", "long exec warn: checks if a censor drops or throttles tls by ClientHello fingerprint",
+		fingerprintTab, false, fingerprintInitMsg{})
+	m.Add("Bypass strategies", "what works here: retries blocked tls handshakes with ClientHello splitting, padding, etc",
+		bypassTab, false, bypassInitMsg{})
+	m.Add("Idle connections", "long exec warn: checks if a censor kills long-lived tls connections (idle timeouts, lifetime limits)",
+		idleTab, false, idleInitMsg{})
+	m.Add("Port filtering", "checks if a censor filters tcp by the destination port (compared with a baseline port on the same ip)",
+		portscanTab, false, portscanInitMsg{})
+	m.Add("DNS injection", "checks if a censor injects dns answers on the path (queries to ips that run no dns server)",
+		dnsinjectTab, false, dnsinjectInitMsg{})
 	return m
 }
 
@@ -75,6 +112,8 @@ func (r *router) TabName() string {
 		return menuCurr.Name
 	case updaterTab:
 		return "Updater"
+	case diagnoseTab:
+		return "Diagnose"
 	case menuTab:
 		return "Menu"
 	}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/netip"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/hyperion-cs/dpi-checkers/ru/dpi-ch/checkers"
@@ -84,6 +86,9 @@ func (rm rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case allInitMsg:
 		rm.router.Tab = allTab
+
+	case diagnoseInitMsg:
+		rm.router.Tab = diagnoseTab
 	}
 
 	if rm.router.Tab == menuTab {
@@ -109,6 +114,45 @@ func (rm rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	rm.dnsModel, cmd = dnsUpdate(rm.dnsModel, msg)
 	cmds = append(cmds, cmd)
 
+	rm.compressionModel, cmd = compressionUpdate(rm.compressionModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.quicModel, cmd = quicUpdate(rm.quicModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.httphostModel, cmd = httphostUpdate(rm.httphostModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.fingerprintModel, cmd = fingerprintUpdate(rm.fingerprintModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.bypassModel, cmd = bypassUpdate(rm.bypassModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.echModel, cmd = echUpdate(rm.echModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.tunnelModel, cmd = tunnelUpdate(rm.tunnelModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.udpvpnModel, cmd = udpvpnUpdate(rm.udpvpnModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.stunModel, cmd = stunUpdate(rm.stunModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.idleModel, cmd = idleUpdate(rm.idleModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.portscanModel, cmd = portscanUpdate(rm.portscanModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.dnsinjectModel, cmd = dnsinjectUpdate(rm.dnsinjectModel, msg)
+	cmds = append(cmds, cmd)
+
+	rm.diagnoseModel, cmd = diagnoseUpdate(rm.diagnoseModel, msg)
+	cmds = append(cmds, cmd)
+
 	rm.syncViewport()
 
 	return rm, tea.Batch(cmds...)
@@ -276,6 +320,11 @@ func webhostUpdate(model webhostModel, msg tea.Msg) (webhostModel, tea.Cmd) {
 	case webhostProducerDoneMsg:
 		model.fetching = false
 		return model, nil
+	case tea.KeyPressMsg:
+		if msg.String() == "enter" {
+			model.detail = !model.detail
+			return model, nil
+		}
 	case spinner.TickMsg:
 		if model.fetching {
 			var cmd tea.Cmd
@@ -314,6 +363,13 @@ func webhostProcessItem(msg webhostItemMsg, model webhostModel) webhostModel {
 		speed = fmt.Sprintf("↑%.1f ↓%.1f", txKbps, rxKbps)
 	}
 
+	downSpeed := "⚠️ skip"
+	if msg.Out.ThroughputDown.RxElapsed > 0 {
+		thp := msg.Out.ThroughputDown
+		const bytesToKilobits = 8.0 / 1_000
+		downSpeed = fmt.Sprintf("↓%.1f", float64(thp.RxBytes)/thp.RxElapsed.Seconds()*bytesToKilobits)
+	}
+
 	row := table.Row{
 		msg.Bag.Name,
 		msg.Out.IpInfo.Org,
@@ -324,10 +380,17 @@ func webhostProcessItem(msg webhostItemMsg, model webhostModel) webhostModel {
 		webhostPrettyAlive(msg.Out.Alive),
 		webhostPrettyTlsV(msg.Out.Tls),
 		webhostPrettySanCn(msg.Out.Tls),
-		webhostPrettyTcp1620(msg.Out.Tcp1620),
+		webhostPrettyTcp1620(msg.Out.Tcp1620) + webhostPrettyTcp1620Cutoff(msg.Out.Tcp1620Cutoff),
+		webhostPrettyTcp1620(msg.Out.Tcp1620Down),
+		webhostPrettyL4_25(msg.Out.L4_25),
 		webhostPrettySiberian(msg.Out.Siberian),
+		webhostPrettyPqKeyShare(msg.Out.PqKeyShare),
 		speed,
+		downSpeed,
+		webhostPrettySniMatrix(msg.Out.SniMatrix),
+		webhostPrettySeries(msg.Out.ThroughputSeries),
 	}
+	model.series[webhostRowKey(row)] = msg.Out.ThroughputSeries
 
 	rows := model.table.Rows()
 	rows = append(rows, row)
@@ -356,8 +419,14 @@ func webhostProcessItem(msg webhostItemMsg, model webhostModel) webhostModel {
 		{Title: "TlsV", Width: tableCellMaxLen(rows, 7, 4)},
 		{Title: "Cert SAN/CN", Width: tableCellMaxLen(rows, 8, 4)},
 		{Title: "Tcp 16-20", Width: tableCellMaxLen(rows, 9, 9)},
-		{Title: "Siberian", Width: tableCellMaxLen(rows, 10, 8)},
-		{Title: "Burst kb/s", Width: tableCellMaxLen(rows, 11, 10)},
+		{Title: "Tcp 16-20 ↓", Width: tableCellMaxLen(rows, 10, 11)},
+		{Title: "L4-25", Width: tableCellMaxLen(rows, 11, 5)},
+		{Title: "Siberian", Width: tableCellMaxLen(rows, 12, 8)},
+		{Title: "PQ hello", Width: tableCellMaxLen(rows, 13, 8)},
+		{Title: "Burst kb/s", Width: tableCellMaxLen(rows, 14, 10)},
+		{Title: "Down kb/s", Width: tableCellMaxLen(rows, 15, 9)},
+		{Title: "SNI matrix", Width: tableCellMaxLen(rows, 16, 10)},
+		{Title: "Thp series", Width: tableCellMaxLen(rows, 17, 10)},
 	}
 
 	model.table.SetColumns(columns)
@@ -388,34 +457,34 @@ func webhostInitModel() webhostModel {
 		fetching: true,
 		table:    t,
 		spinner:  spin,
+		series:   map[string]*checkers.WebhostThroughputSeries{},
 	}
 }
 
-func allUpdate(model allModel, msg tea.Msg) (allModel, tea.Cmd) {
+func quicUpdate(model quicModel, msg tea.Msg) (quicModel, tea.Cmd) {
 	if !model.inited {
 		switch msg.(type) {
-		case allInitMsg:
-			ctx, cancel := context.WithCancel(context.Background())
-
-			spin := spinner.New()
-			spin.Spinner = spinnerType
-			spin.Style = spinnerStyle
-
-			model = allModel{inited: true, spinner: spin, ctx: ctx, cancel: cancel, fetching: true}
-			return model, tea.Batch(model.spinner.Tick, allProducerStartCmd(model.ctx))
+		case quicInitMsg:
+			model := quicInitModel()
+			return model, tea.Batch(model.spinner.Tick, quicProducerStartCmd(model.ctx))
 		}
 
 		return model, nil
 	}
 
 	switch msg := msg.(type) {
-	case allProducerStartedMsg:
+	case quicProducerStartedMsg:
 		model.out = msg.out
-		return model, allConsumerCmd(model.out)
-	case allProgressMsg:
-		model.progress = checkers.FullCheckProgress(msg)
-		return model, allConsumerCmd(model.out)
-	case allProducerDoneMsg:
+		return model, quicConsumerCmd(model.out)
+	case quicItemMsg:
+		return quicProcessItem(msg, model), tea.Batch(quicConsumerCmd(model.out), tea.ClearScreen)
+	case quicProgressMsg:
+		model.progress = string(msg)
+		if strings.Contains(model.progress, "farming timeout") { // TODO: make it typed
+			model.farmTimeout = true
+		}
+		return model, quicConsumerCmd(model.out)
+	case quicProducerDoneMsg:
 		model.fetching = false
 		return model, nil
 	case spinner.TickMsg:
@@ -428,58 +497,107 @@ func allUpdate(model allModel, msg tea.Msg) (allModel, tea.Cmd) {
 		if model.cancel != nil {
 			model.cancel()
 		}
-		model = allModel{}
+		model = quicModel{}
 		return model, nil
 	}
 
-	return model, nil
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
 }
 
-func dnsUpdate(model dnsModel, msg tea.Msg) (dnsModel, tea.Cmd) {
+func quicProcessItem(msg quicItemMsg, model quicModel) quicModel {
+	cfg := config.Get().Checkers.Quic
+
+	model.progress = fmt.Sprintf(`quic checker => for "%s" host is ready: %v`, msg.Bag.Name, msg.Out.IpInfo.Ip)
+
+	row := table.Row{
+		msg.Bag.Name,
+		msg.Out.IpInfo.Org,
+		fmt.Sprintf("AS%d", msg.Out.IpInfo.Asn),
+		countryIsoToFlagEmoji(msg.Out.IpInfo.CountryIso) + " " + msg.Out.IpInfo.CountryIso,
+		msg.Out.IpInfo.Ip.String(),
+		webhostPrettyAlive(msg.Out.Alive),
+		quicPrettyHandshake(msg.Out.Quic),
+		quicPrettyVersion(msg.Out.QuicVersion),
+		quicPrettyVerdict(msg.Out.Verdict),
+	}
+
+	rows := model.table.Rows()
+	rows = append(rows, row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[4], b[4])) // by group, then by ip
+	})
+
+	columns := []table.Column{
+		{Title: "Group", Width: tableCellMaxLen(rows, 0, 5)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 1, 3)},
+		{Title: "AS", Width: tableCellMaxLen(rows, 2, 7)},
+		{Title: "Loc", Width: 5},
+		{Title: "IP", Width: tableCellMaxLen(rows, 4, 2)},
+		{Title: "TCP/TLS", Width: tableCellMaxLen(rows, 5, 7)},
+		{Title: "QUIC", Width: tableCellMaxLen(rows, 6, 4)},
+		{Title: "QuicV", Width: tableCellMaxLen(rows, 7, 5)},
+		{Title: "Verdict", Width: tableCellMaxLen(rows, 8, 7)},
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func quicInitModel() quicModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return quicModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func fingerprintUpdate(model fingerprintModel, msg tea.Msg) (fingerprintModel, tea.Cmd) {
 	if !model.inited {
 		switch msg.(type) {
-		case dnsInitMsg:
-			model = dnsInitModel()
-			return model, tea.Batch(model.spinner.Tick, dnsProducerStartCmd(model.ctx))
+		case fingerprintInitMsg:
+			model := fingerprintInitModel()
+			return model, tea.Batch(model.spinner.Tick, fingerprintProducerStartCmd(model.ctx))
 		}
 
 		return model, nil
 	}
 
 	switch msg := msg.(type) {
-	case dnsProducerStartedMsg:
+	case fingerprintProducerStartedMsg:
 		model.out = msg.out
-		return model, dnsConsumerCmd(model.out)
-	case dnsProviderPlainMsg:
-		return dnsProcessPlainProvider(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
-	case dnsProviderDohMsg:
-		return dnsProcessDohProvider(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
-	case dnsLeakMsg:
-		return dnsProcessLeak(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
-	case dnsProgressMsg:
+		return model, fingerprintConsumerCmd(model.out)
+	case fingerprintItemMsg:
+		return fingerprintProcessItem(msg, model), tea.Batch(fingerprintConsumerCmd(model.out), tea.ClearScreen)
+	case fingerprintProgressMsg:
 		model.progress = string(msg)
-		return model, dnsConsumerCmd(model.out)
-	case dnsProducerDoneMsg:
-		model.fetching = false
-		if model.out.progress != nil {
-			close(model.out.progress)
+		if strings.Contains(model.progress, "farming timeout") { // TODO: make it typed
+			model.farmTimeout = true
 		}
+		return model, fingerprintConsumerCmd(model.out)
+	case fingerprintProducerDoneMsg:
+		model.fetching = false
 		return model, nil
-	case tea.KeyPressMsg:
-		switch normKey(msg.String()) {
-		case "left":
-			model.providerTable.Focus()
-			model.providerTable.SetStyles(tableStyle(true))
-			model.leakTable.Blur()
-			model.leakTable.SetStyles(tableStyle(false))
-			return model, nil
-		case "right":
-			model.leakTable.Focus()
-			model.leakTable.SetStyles(tableStyle(true))
-			model.providerTable.Blur()
-			model.providerTable.SetStyles(tableStyle(false))
-			return model, nil
-		}
 	case spinner.TickMsg:
 		if model.fetching {
 			var cmd tea.Cmd
@@ -490,135 +608,1390 @@ func dnsUpdate(model dnsModel, msg tea.Msg) (dnsModel, tea.Cmd) {
 		if model.cancel != nil {
 			model.cancel()
 		}
-		model = dnsModel{}
+		model = fingerprintModel{}
 		return model, nil
 	}
 
-	var leakCmd, providerCmd tea.Cmd
-	model.leakTable, leakCmd = model.leakTable.Update(msg)
-	model.providerTable, providerCmd = model.providerTable.Update(msg)
-	return model, tea.Batch(leakCmd, providerCmd)
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
 }
 
-func dnsProcessPlainProvider(msg dnsProviderPlainMsg, model dnsModel) dnsModel {
-	model.out.progress <- fmt.Sprintf("[%s] plain: %s", msg.Provider, dnsPrettyProviderVerdict(msg.Verdict))
-	v, ok := model.providerRows[msg.Provider]
-	if !ok {
-		v.dohVerdict = ErrPending
-	}
-	v.plainVerdict = msg.Verdict
-	model.providerRows[msg.Provider] = v
-	return dnsUpdateProviderTable(model)
-}
+func fingerprintProcessItem(msg fingerprintItemMsg, model fingerprintModel) fingerprintModel {
+	cfg := config.Get().Checkers.Fingerprint
 
-func dnsProcessDohProvider(msg dnsProviderDohMsg, model dnsModel) dnsModel {
-	model.out.progress <- fmt.Sprintf("[%s] doh: %s", msg.Provider, dnsPrettyProviderVerdict(msg.Verdict))
-	v, ok := model.providerRows[msg.Provider]
-	if !ok {
-		v.plainVerdict = ErrPending
-	}
-	v.dohVerdict = msg.Verdict
-	model.providerRows[msg.Provider] = v
-	return dnsUpdateProviderTable(model)
-}
+	model.progress = fmt.Sprintf(`fingerprint checker => for "%s" host is ready: %v`, msg.Bag.Name, msg.Out.IpInfo.Ip)
 
-func dnsProcessLeak(msg dnsLeakMsg, model dnsModel) dnsModel {
-	if msg.Err != nil {
-		model.out.progress <- "dns leak internal err"
-		return model
+	row := table.Row{
+		msg.Bag.Name,
+		msg.Out.IpInfo.Org,
+		fmt.Sprintf("AS%d", msg.Out.IpInfo.Asn),
+		countryIsoToFlagEmoji(msg.Out.IpInfo.CountryIso) + " " + msg.Out.IpInfo.CountryIso,
+		msg.Out.IpInfo.Ip.String(),
 	}
 
-	model.out.progress <- "dns leak received"
-	cfg := config.Get().Checkers.Dns
-
-	newRows := []table.Row{}
-	for _, item := range msg.Items {
-		newRows = append(newRows, table.Row{
-			item.Ip,
-			item.Subnet,
-			item.Asn,
-			item.Org,
-			fmt.Sprintf("%s %s", countryIsoToFlagEmoji(item.Location), item.Location),
-		})
+	// one cell per fingerprint: "http/1.1 alpn / original alpn"
+	verdicts := map[string][2]string{}
+	for _, item := range msg.Out.Items {
+		v := verdicts[item.Fingerprint]
+		if item.OriginalAlpn {
+			v[1] = fingerprintPrettyVerdict(item.Verdict)
+		} else {
+			v[0] = fingerprintPrettyVerdict(item.Verdict)
+		}
+		verdicts[item.Fingerprint] = v
+	}
+	names := checkers.FingerprintNames()
+	for _, name := range names {
+		row = append(row, verdicts[name][0]+" / "+verdicts[name][1])
 	}
-	rows := append(model.leakTable.Rows(), newRows...)
 
-	// by ip
+	rows := model.table.Rows()
+	rows = append(rows, row)
 	slices.SortFunc(rows, func(a, b table.Row) int {
-		return netip.MustParseAddr(a[0]).Compare(netip.MustParseAddr(b[0]))
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[4], b[4])) // by group, then by ip
 	})
-	rows = slices.CompactFunc(rows, func(a, b table.Row) bool { return a[0] == b[0] })
 
 	columns := []table.Column{
-		{Title: "IP", Width: tableCellMaxLen(rows, 0, 2)},
-		{Title: "Prefix", Width: tableCellMaxLen(rows, 1, 6)},
+		{Title: "Group", Width: tableCellMaxLen(rows, 0, 5)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 1, 3)},
 		{Title: "AS", Width: tableCellMaxLen(rows, 2, 7)},
-		{Title: "Org", Width: tableCellMaxLen(rows, 3, 3)},
-		{Title: "Location", Width: 8},
-	}
-
-	model.leakTable.SetColumns(columns)
-	model.leakTable.SetRows(rows)
-
-	model.tblHeight = max(model.tblHeight, tableHeight(model.leakTable.Rows(), cfg.TableMaxVisibleRows))
-	model.leakTable.SetHeight(model.tblHeight)
-	model.providerTable.SetHeight(model.tblHeight)
-	model.leakTable.SetWidth(tableWidth(model.leakTable.Columns()))
-
-	return model
-}
-
-func dnsUpdateProviderTable(model dnsModel) dnsModel {
-	cfg := config.Get().Checkers.Dns
-	rows := []table.Row{}
-
-	for id, s := range model.providerRows {
-		p := dnsPrettyProviderVerdict(s.plainVerdict)
-		doh := dnsPrettyProviderVerdict(s.dohVerdict)
-		row := table.Row{id, p, doh}
-		rows = append(rows, row)
+		{Title: "Loc", Width: 5},
+		{Title: "IP", Width: tableCellMaxLen(rows, 4, 2)},
 	}
-
-	slices.SortFunc(rows, func(a, b table.Row) int {
-		return strings.Compare(a[0], b[0]) // by provider name
-	})
-	columns := []table.Column{
-		{Title: "Provider", Width: tableCellMaxLen(rows, 0, 14)},
-		{Title: "Plain", Width: tableCellMaxLen(rows, 1, 14)},
-		{Title: "DoH", Width: tableCellMaxLen(rows, 2, 14)},
+	for i, name := range names {
+		columns = append(columns, table.Column{Title: name, Width: tableCellMaxLen(rows, 5+i, len(name))})
 	}
 
-	model.providerTable.SetColumns(columns)
-	model.providerTable.SetRows(rows)
-
-	model.tblHeight = max(model.tblHeight, tableHeight(model.providerTable.Rows(), cfg.TableMaxVisibleRows))
-	model.providerTable.SetHeight(model.tblHeight)
-	model.leakTable.SetHeight(model.tblHeight)
-	model.providerTable.SetWidth(tableWidth(model.providerTable.Columns()))
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
 
 	return model
 }
 
-func dnsInitModel() dnsModel {
+func fingerprintInitModel() fingerprintModel {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	spin := spinner.New()
 	spin.Spinner = spinnerType
 	spin.Style = spinnerStyle
 
-	providerTable := table.New(
+	t := table.New(
 		table.WithFocused(true),
 		table.WithStyles(tableStyle(true)),
 		table.WithKeyMap(tableKeyMap()),
 	)
 
-	leakTable := table.New(
-		table.WithFocused(false),
-		table.WithStyles(tableStyle(false)),
-		table.WithKeyMap(tableKeyMap()),
-	)
-
-	return dnsModel{
+	return fingerprintModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func bypassUpdate(model bypassModel, msg tea.Msg) (bypassModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case bypassInitMsg:
+			model := bypassInitModel()
+			return model, tea.Batch(model.spinner.Tick, bypassProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case bypassProducerStartedMsg:
+		model.out = msg.out
+		return model, bypassConsumerCmd(model.out)
+	case bypassItemMsg:
+		return bypassProcessItem(msg, model), tea.Batch(bypassConsumerCmd(model.out), tea.ClearScreen)
+	case bypassProgressMsg:
+		model.progress = string(msg)
+		if strings.Contains(model.progress, "farming timeout") { // TODO: make it typed
+			model.farmTimeout = true
+		}
+		return model, bypassConsumerCmd(model.out)
+	case bypassProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = bypassModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func bypassProcessItem(msg bypassItemMsg, model bypassModel) bypassModel {
+	cfg := config.Get().Checkers.Bypass
+
+	model.progress = fmt.Sprintf(`bypass checker => for "%s" host is ready: %v`, msg.Bag.Name, msg.Out.IpInfo.Ip)
+
+	row := table.Row{
+		msg.Bag.Name,
+		msg.Out.IpInfo.Org,
+		fmt.Sprintf("AS%d", msg.Out.IpInfo.Asn),
+		countryIsoToFlagEmoji(msg.Out.IpInfo.CountryIso) + " " + msg.Out.IpInfo.CountryIso,
+		msg.Out.IpInfo.Ip.String(),
+		bypassPrettyBaseline(msg.Out.Baseline),
+	}
+	for _, item := range msg.Out.Strategies {
+		row = append(row, bypassPrettyStrategy(item.Err))
+	}
+
+	rows := model.table.Rows()
+	rows = append(rows, row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[4], b[4])) // by group, then by ip
+	})
+
+	columns := []table.Column{
+		{Title: "Group", Width: tableCellMaxLen(rows, 0, 5)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 1, 3)},
+		{Title: "AS", Width: tableCellMaxLen(rows, 2, 7)},
+		{Title: "Loc", Width: 5},
+		{Title: "IP", Width: tableCellMaxLen(rows, 4, 2)},
+		{Title: "Baseline", Width: tableCellMaxLen(rows, 5, 8)},
+	}
+	for i, strategy := range cfg.Strategies {
+		columns = append(columns, table.Column{Title: strategy, Width: tableCellMaxLen(rows, 6+i, len(strategy))})
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func bypassInitModel() bypassModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return bypassModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func echUpdate(model echModel, msg tea.Msg) (echModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case echInitMsg:
+			model := echInitModel()
+			return model, tea.Batch(model.spinner.Tick, echProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case echProducerStartedMsg:
+		model.out = msg.out
+		return model, echConsumerCmd(model.out)
+	case echItemMsg:
+		return echProcessItem(msg, model), tea.Batch(echConsumerCmd(model.out), tea.ClearScreen)
+	case echProgressMsg:
+		model.progress = string(msg)
+		if strings.Contains(model.progress, "farming timeout") { // TODO: make it typed
+			model.farmTimeout = true
+		}
+		return model, echConsumerCmd(model.out)
+	case echProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = echModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func echProcessItem(msg echItemMsg, model echModel) echModel {
+	cfg := config.Get().Checkers.Ech
+
+	model.progress = fmt.Sprintf(`ech checker => for "%s" host is ready: %v`, msg.Bag.Name, msg.Out.IpInfo.Ip)
+
+	row := table.Row{
+		msg.Bag.Name,
+		msg.Out.IpInfo.Org,
+		fmt.Sprintf("AS%d", msg.Out.IpInfo.Asn),
+		countryIsoToFlagEmoji(msg.Out.IpInfo.CountryIso) + " " + msg.Out.IpInfo.CountryIso,
+		msg.Out.IpInfo.Ip.String(),
+		msg.Out.Sni,
+		webhostPrettyAlive(msg.Out.Baseline),
+		echPrettyHandshake(msg.Out.Ech),
+		echPrettyVerdict(msg.Out.Verdict),
+	}
+
+	rows := model.table.Rows()
+	rows = append(rows, row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[4], b[4])) // by group, then by ip
+	})
+
+	columns := []table.Column{
+		{Title: "Group", Width: tableCellMaxLen(rows, 0, 5)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 1, 3)},
+		{Title: "AS", Width: tableCellMaxLen(rows, 2, 7)},
+		{Title: "Loc", Width: 5},
+		{Title: "IP", Width: tableCellMaxLen(rows, 4, 2)},
+		{Title: "SNI", Width: tableCellMaxLen(rows, 5, 3)},
+		{Title: "Non-ECH", Width: tableCellMaxLen(rows, 6, 7)},
+		{Title: "ECH", Width: tableCellMaxLen(rows, 7, 3)},
+		{Title: "Verdict", Width: tableCellMaxLen(rows, 8, 7)},
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func echInitModel() echModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return echModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func idleUpdate(model idleModel, msg tea.Msg) (idleModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case idleInitMsg:
+			model := idleInitModel()
+			return model, tea.Batch(model.spinner.Tick, idleProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case idleProducerStartedMsg:
+		model.out = msg.out
+		return model, idleConsumerCmd(model.out)
+	case idleItemMsg:
+		return idleProcessItem(msg, model), tea.Batch(idleConsumerCmd(model.out), tea.ClearScreen)
+	case idleProgressMsg:
+		model.progress = string(msg)
+		if strings.Contains(model.progress, "farming timeout") { // TODO: make it typed
+			model.farmTimeout = true
+		}
+		return model, idleConsumerCmd(model.out)
+	case idleProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = idleModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func idleProcessItem(msg idleItemMsg, model idleModel) idleModel {
+	cfg := config.Get().Checkers.Idle
+
+	model.progress = fmt.Sprintf(`idle checker => for "%s" host is ready: %v`, msg.Bag.Name, msg.Out.IpInfo.Ip)
+
+	row := table.Row{
+		msg.Bag.Name,
+		msg.Out.IpInfo.Org,
+		fmt.Sprintf("AS%d", msg.Out.IpInfo.Asn),
+		countryIsoToFlagEmoji(msg.Out.IpInfo.CountryIso) + " " + msg.Out.IpInfo.CountryIso,
+		msg.Out.IpInfo.Ip.String(),
+		msg.Out.Sni,
+	}
+	for _, keepalive := range cfg.Keepalives {
+		row = append(row, idlePrettyLongest(msg.Out, keepalive))
+	}
+
+	rows := model.table.Rows()
+	rows = append(rows, row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[4], b[4])) // by group, then by ip
+	})
+
+	columns := []table.Column{
+		{Title: "Group", Width: tableCellMaxLen(rows, 0, 5)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 1, 3)},
+		{Title: "AS", Width: tableCellMaxLen(rows, 2, 7)},
+		{Title: "Loc", Width: 5},
+		{Title: "IP", Width: tableCellMaxLen(rows, 4, 2)},
+		{Title: "SNI", Width: tableCellMaxLen(rows, 5, 3)},
+	}
+	for i, keepalive := range cfg.Keepalives {
+		title := "Keepalive: " + keepalive
+		columns = append(columns, table.Column{Title: title, Width: tableCellMaxLen(rows, 6+i, len(title))})
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func idleInitModel() idleModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return idleModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func portscanUpdate(model portscanModel, msg tea.Msg) (portscanModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case portscanInitMsg:
+			model := portscanInitModel()
+			return model, tea.Batch(model.spinner.Tick, portscanProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case portscanProducerStartedMsg:
+		model.out = msg.out
+		return model, portscanConsumerCmd(model.out)
+	case portscanItemMsg:
+		return portscanProcessItem(msg, model), tea.Batch(portscanConsumerCmd(model.out), tea.ClearScreen)
+	case portscanProgressMsg:
+		model.progress = string(msg)
+		if strings.Contains(model.progress, "farming timeout") { // TODO: make it typed
+			model.farmTimeout = true
+		}
+		return model, portscanConsumerCmd(model.out)
+	case portscanProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = portscanModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func portscanProcessItem(msg portscanItemMsg, model portscanModel) portscanModel {
+	cfg := config.Get().Checkers.Portscan
+
+	model.progress = fmt.Sprintf(`portscan checker => for "%s" host is ready: %v`, msg.Bag.Name, msg.Out.IpInfo.Ip)
+
+	row := table.Row{
+		msg.Bag.Name,
+		msg.Out.IpInfo.Org,
+		fmt.Sprintf("AS%d", msg.Out.IpInfo.Asn),
+		countryIsoToFlagEmoji(msg.Out.IpInfo.CountryIso) + " " + msg.Out.IpInfo.CountryIso,
+		msg.Out.IpInfo.Ip.String(),
+		fmt.Sprintf("%d: %s", msg.Out.Baseline.Port, portscanPrettyVerdict(msg.Out.Baseline.Verdict)),
+	}
+	for _, item := range msg.Out.Items {
+		row = append(row, portscanPrettyVerdict(item.Verdict))
+	}
+
+	rows := model.table.Rows()
+	rows = append(rows, row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[4], b[4])) // by group, then by ip
+	})
+
+	columns := []table.Column{
+		{Title: "Group", Width: tableCellMaxLen(rows, 0, 5)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 1, 3)},
+		{Title: "AS", Width: tableCellMaxLen(rows, 2, 7)},
+		{Title: "Loc", Width: 5},
+		{Title: "IP", Width: tableCellMaxLen(rows, 4, 2)},
+		{Title: "Baseline", Width: tableCellMaxLen(rows, 5, 8)},
+	}
+	for i, port := range cfg.Ports {
+		title := fmt.Sprintf("Port: %d", port)
+		columns = append(columns, table.Column{Title: title, Width: tableCellMaxLen(rows, 6+i, len(title))})
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func portscanInitModel() portscanModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return portscanModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func dnsinjectUpdate(model dnsinjectModel, msg tea.Msg) (dnsinjectModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case dnsinjectInitMsg:
+			model := dnsinjectInitModel()
+			return model, tea.Batch(model.spinner.Tick, dnsinjectProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case dnsinjectProducerStartedMsg:
+		model.out = msg.out
+		return model, dnsinjectConsumerCmd(model.out)
+	case dnsinjectItemMsg:
+		return dnsinjectProcessItem(msg, model), tea.Batch(dnsinjectConsumerCmd(model.out), tea.ClearScreen)
+	case dnsinjectProgressMsg:
+		model.progress = string(msg)
+		if strings.Contains(model.progress, "farming timeout") { // TODO: make it typed
+			model.farmTimeout = true
+		}
+		return model, dnsinjectConsumerCmd(model.out)
+	case dnsinjectProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = dnsinjectModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func dnsinjectProcessItem(msg dnsinjectItemMsg, model dnsinjectModel) dnsinjectModel {
+	cfg := config.Get().Checkers.Dnsinject
+
+	model.progress = fmt.Sprintf(`dnsinject checker => for "%s" host is ready: %v`, msg.Bag.Name, msg.Out.IpInfo.Ip)
+
+	rows := model.table.Rows()
+	items := append(slices.Clone(msg.Out.Controls), msg.Out.Items...)
+	for i, item := range items {
+		domain := item.Domain
+		if i < len(msg.Out.Controls) {
+			domain += " (control)"
+		}
+		rows = append(rows, table.Row{
+			msg.Bag.Name,
+			msg.Out.IpInfo.Ip.String(),
+			msg.Out.IpInfo.Org,
+			domain,
+			dnsinjectPrettyVerdict(item.Verdict),
+			dnsinjectPrettyAnswers(item),
+		})
+	}
+	slices.SortStableFunc(rows, func(a, b table.Row) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1])) // by group, then by ip
+	})
+
+	columns := []table.Column{
+		{Title: "Group", Width: tableCellMaxLen(rows, 0, 5)},
+		{Title: "IP", Width: tableCellMaxLen(rows, 1, 2)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 2, 3)},
+		{Title: "Domain", Width: tableCellMaxLen(rows, 3, 6)},
+		{Title: "Verdict", Width: tableCellMaxLen(rows, 4, 7)},
+		{Title: "Injected answer", Width: tableCellMaxLen(rows, 5, 15)},
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func dnsinjectInitModel() dnsinjectModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return dnsinjectModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func httphostUpdate(model httphostModel, msg tea.Msg) (httphostModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case httphostInitMsg:
+			model = httphostInitModel()
+			return model, tea.Batch(model.spinner.Tick, httphostProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case httphostProducerStartedMsg:
+		model.out = msg.out
+		return model, httphostConsumerCmd(model.out)
+	case httphostItemMsg:
+		return httphostProcessItem(msg, model), tea.Batch(httphostConsumerCmd(model.out), tea.ClearScreen)
+	case httphostProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = httphostModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func httphostProcessItem(msg httphostItemMsg, model httphostModel) httphostModel {
+	cfg := config.Get().Checkers.HttpHost
+	model.progress = fmt.Sprintf(`httphost checker => "%s" is ready`, msg.Target)
+
+	row := table.Row{msg.Target, " — ", httphostPrettyLookup(msg.Err), "", ""}
+	if msg.Err == nil {
+		row = table.Row{
+			msg.Target,
+			msg.Ip.String(),
+			httphostPrettyProbe(msg.Real),
+			httphostPrettyProbe(msg.Random),
+			httphostPrettyProbe(msg.Mangled),
+		}
+	}
+
+	rows := append(model.table.Rows(), row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Compare(a[0], b[0]) // by target
+	})
+
+	columns := []table.Column{
+		{Title: "Target", Width: tableCellMaxLen(rows, 0, 6)},
+		{Title: "IP", Width: tableCellMaxLen(rows, 1, 2)},
+		{Title: "Real Host", Width: tableCellMaxLen(rows, 2, 9)},
+		{Title: "Random Host", Width: tableCellMaxLen(rows, 3, 11)},
+		{Title: "Mangled Host", Width: tableCellMaxLen(rows, 4, 12)},
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func httphostInitModel() httphostModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return httphostModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func tunnelUpdate(model tunnelModel, msg tea.Msg) (tunnelModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case tunnelInitMsg:
+			model = tunnelInitModel()
+			return model, tea.Batch(model.spinner.Tick, tunnelProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case tunnelProducerStartedMsg:
+		model.out = msg.out
+		return model, tunnelConsumerCmd(model.out)
+	case tunnelItemMsg:
+		return tunnelProcessItem(msg, model), tea.Batch(tunnelConsumerCmd(model.out), tea.ClearScreen)
+	case tunnelProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = tunnelModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func tunnelProcessItem(msg tunnelItemMsg, model tunnelModel) tunnelModel {
+	cfg := config.Get().Checkers.Tunnel
+	model.progress = fmt.Sprintf(`tunnel checker => "%s" is ready`, msg.Name)
+
+	endpoint := net.JoinHostPort(msg.Host, strconv.Itoa(msg.Port))
+	row := table.Row{msg.Name, endpoint, " — ", httphostPrettyLookup(msg.Err)}
+	for range cfg.Protocols {
+		row = append(row, "")
+	}
+	if msg.Err == nil {
+		row = table.Row{msg.Name, endpoint, msg.Ip.String(), tunnelPrettyResult(msg.Control)}
+		for _, p := range msg.Probes {
+			row = append(row, tunnelPrettyProbe(p))
+		}
+	}
+
+	rows := append(model.table.Rows(), row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Compare(a[0], b[0]) // by name
+	})
+
+	columns := []table.Column{
+		{Title: "Name", Width: tableCellMaxLen(rows, 0, 4)},
+		{Title: "Endpoint", Width: tableCellMaxLen(rows, 1, 8)},
+		{Title: "IP", Width: tableCellMaxLen(rows, 2, 2)},
+		{Title: "Control", Width: tableCellMaxLen(rows, 3, 7)},
+	}
+	for i, protocol := range cfg.Protocols {
+		columns = append(columns, table.Column{Title: protocol, Width: tableCellMaxLen(rows, 4+i, len(protocol))})
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func tunnelInitModel() tunnelModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return tunnelModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func udpvpnUpdate(model udpvpnModel, msg tea.Msg) (udpvpnModel, tea.Cmd) {
+	if !model.inited {
+		switch msg := msg.(type) {
+		case udpvpnInitMsg:
+			model = udpvpnInitModel()
+			return model, tea.Batch(model.spinner.Tick, udpvpnProducerStartCmd(model.ctx, msg.Targets))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case udpvpnProducerStartedMsg:
+		model.out = msg.out
+		return model, udpvpnConsumerCmd(model.out)
+	case udpvpnItemMsg:
+		return udpvpnProcessItem(msg, model), tea.Batch(udpvpnConsumerCmd(model.out), tea.ClearScreen)
+	case udpvpnProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = udpvpnModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func udpvpnProcessItem(msg udpvpnItemMsg, model udpvpnModel) udpvpnModel {
+	cfg := config.Get().Checkers.Udpvpn
+	model.progress = fmt.Sprintf(`udpvpn checker => "%s" is ready`, msg.Name)
+
+	endpoint := net.JoinHostPort(msg.Host, strconv.Itoa(msg.Port))
+	row := table.Row{msg.Name, endpoint, " — ", httphostPrettyLookup(msg.Err)}
+	for range cfg.Protocols {
+		row = append(row, "")
+	}
+	if msg.Err == nil {
+		row = table.Row{msg.Name, endpoint, msg.Ip.String(), udpvpnPrettyResult(msg.Control)}
+		for _, p := range msg.Probes {
+			row = append(row, udpvpnPrettyProbe(p))
+		}
+	}
+
+	rows := append(model.table.Rows(), row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Compare(a[0], b[0]) // by name
+	})
+
+	columns := []table.Column{
+		{Title: "Name", Width: tableCellMaxLen(rows, 0, 4)},
+		{Title: "Endpoint", Width: tableCellMaxLen(rows, 1, 8)},
+		{Title: "IP", Width: tableCellMaxLen(rows, 2, 2)},
+		{Title: "Control", Width: tableCellMaxLen(rows, 3, 7)},
+	}
+	for i, protocol := range cfg.Protocols {
+		columns = append(columns, table.Column{Title: protocol, Width: tableCellMaxLen(rows, 4+i, len(protocol))})
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func udpvpnInitModel() udpvpnModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return udpvpnModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func diagnoseUpdate(model diagnoseModel, msg tea.Msg) (diagnoseModel, tea.Cmd) {
+	if !model.inited {
+		switch msg := msg.(type) {
+		case diagnoseInitMsg:
+			ctx, cancel := context.WithCancel(context.Background())
+
+			spin := spinner.New()
+			spin.Spinner = spinnerType
+			spin.Style = spinnerStyle
+
+			model = diagnoseModel{inited: true, spinner: spin, ctx: ctx, cancel: cancel, fetching: true, host: msg.Host}
+			return model, tea.Batch(model.spinner.Tick, diagnoseProducerStartCmd(model.ctx, model.host))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case diagnoseProducerStartedMsg:
+		model.out = msg.out
+		return model, diagnoseConsumerCmd(model.out)
+	case diagnoseStepMsg:
+		model.steps = append(model.steps, checkers.DiagnoseStep(msg))
+		return model, tea.Batch(diagnoseConsumerCmd(model.out), tea.ClearScreen)
+	case diagnoseProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = diagnoseModel{}
+		return model, nil
+	}
+
+	return model, nil
+}
+
+func stunUpdate(model stunModel, msg tea.Msg) (stunModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case stunInitMsg:
+			model = stunInitModel()
+			return model, tea.Batch(model.spinner.Tick, stunProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case stunProducerStartedMsg:
+		model.out = msg.out
+		return model, stunConsumerCmd(model.out)
+	case stunItemMsg:
+		return stunProcessItem(msg, model), tea.Batch(stunConsumerCmd(model.out), tea.ClearScreen)
+	case stunProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = stunModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func stunProcessItem(msg stunItemMsg, model stunModel) stunModel {
+	cfg := config.Get().Checkers.Stun
+	model.progress = fmt.Sprintf(`stun checker => "%s" is ready`, msg.Name)
+
+	endpoint := net.JoinHostPort(msg.Host, strconv.Itoa(msg.Port))
+	row := table.Row{msg.Name, endpoint, " — ", "", "", "", httphostPrettyLookup(msg.Err)}
+	if msg.Err == nil {
+		mapped := " — "
+		if msg.Udp.Mapped.IsValid() {
+			mapped = msg.Udp.Mapped.String()
+		} else if msg.Tcp.Mapped.IsValid() {
+			mapped = msg.Tcp.Mapped.String()
+		}
+		row = table.Row{
+			msg.Name,
+			endpoint,
+			msg.Ip.String(),
+			stunPrettyTransport(msg.Udp),
+			stunPrettyTransport(msg.Tcp),
+			mapped,
+			stunPrettyVerdict(msg.Verdict),
+		}
+	}
+
+	rows := append(model.table.Rows(), row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Compare(a[0], b[0]) // by name
+	})
+
+	columns := []table.Column{
+		{Title: "Name", Width: tableCellMaxLen(rows, 0, 4)},
+		{Title: "Server", Width: tableCellMaxLen(rows, 1, 6)},
+		{Title: "IP", Width: tableCellMaxLen(rows, 2, 2)},
+		{Title: "UDP", Width: tableCellMaxLen(rows, 3, 3)},
+		{Title: "TCP", Width: tableCellMaxLen(rows, 4, 3)},
+		{Title: "Mapped", Width: tableCellMaxLen(rows, 5, 6)},
+		{Title: "Verdict", Width: tableCellMaxLen(rows, 6, 7)},
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func stunInitModel() stunModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return stunModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
+
+func allUpdate(model allModel, msg tea.Msg) (allModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case allInitMsg:
+			ctx, cancel := context.WithCancel(context.Background())
+
+			spin := spinner.New()
+			spin.Spinner = spinnerType
+			spin.Style = spinnerStyle
+
+			model = allModel{inited: true, spinner: spin, ctx: ctx, cancel: cancel, fetching: true}
+			return model, tea.Batch(model.spinner.Tick, allProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case allProducerStartedMsg:
+		model.out = msg.out
+		return model, allConsumerCmd(model.out)
+	case allProgressMsg:
+		model.progress = checkers.FullCheckProgress(msg)
+		return model, allConsumerCmd(model.out)
+	case allProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = allModel{}
+		return model, nil
+	}
+
+	return model, nil
+}
+
+func dnsUpdate(model dnsModel, msg tea.Msg) (dnsModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case dnsInitMsg:
+			model = dnsInitModel()
+			return model, tea.Batch(model.spinner.Tick, dnsProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case dnsProducerStartedMsg:
+		model.out = msg.out
+		return model, dnsConsumerCmd(model.out)
+	case dnsProviderPlainMsg:
+		return dnsProcessPlainProvider(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
+	case dnsProviderDohMsg:
+		return dnsProcessDohProvider(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
+	case dnsProviderDotMsg:
+		return dnsProcessDotProvider(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
+	case dnsProviderDoqMsg:
+		return dnsProcessDoqProvider(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
+	case dnsProviderDoh3Msg:
+		return dnsProcessDoh3Provider(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
+	case dnsLeakMsg:
+		return dnsProcessLeak(msg, model), tea.Batch(dnsConsumerCmd(model.out), tea.ClearScreen)
+	case dnsProgressMsg:
+		model.progress = string(msg)
+		return model, dnsConsumerCmd(model.out)
+	case dnsProducerDoneMsg:
+		model.fetching = false
+		if model.out.progress != nil {
+			close(model.out.progress)
+		}
+		return model, nil
+	case tea.KeyPressMsg:
+		switch normKey(msg.String()) {
+		case "left":
+			model.providerTable.Focus()
+			model.providerTable.SetStyles(tableStyle(true))
+			model.leakTable.Blur()
+			model.leakTable.SetStyles(tableStyle(false))
+			return model, nil
+		case "right":
+			model.leakTable.Focus()
+			model.leakTable.SetStyles(tableStyle(true))
+			model.providerTable.Blur()
+			model.providerTable.SetStyles(tableStyle(false))
+			return model, nil
+		}
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = dnsModel{}
+		return model, nil
+	}
+
+	var leakCmd, providerCmd tea.Cmd
+	model.leakTable, leakCmd = model.leakTable.Update(msg)
+	model.providerTable, providerCmd = model.providerTable.Update(msg)
+	return model, tea.Batch(leakCmd, providerCmd)
+}
+
+func dnsProcessPlainProvider(msg dnsProviderPlainMsg, model dnsModel) dnsModel {
+	model.out.progress <- fmt.Sprintf("[%s] plain: udp %s, tcp %s", msg.Provider,
+		dnsPrettyProviderVerdict(msg.Udp), dnsPrettyProviderVerdict(msg.Tcp))
+	v, ok := model.providerRows[msg.Provider]
+	if !ok {
+		v = dnsPendingVerdicts()
+	}
+	v.udpVerdict = msg.Udp
+	v.tcpVerdict = msg.Tcp
+	model.providerRows[msg.Provider] = v
+	return dnsUpdateProviderTable(model)
+}
+
+func dnsProcessDohProvider(msg dnsProviderDohMsg, model dnsModel) dnsModel {
+	model.out.progress <- fmt.Sprintf("[%s] doh: %s", msg.Provider, dnsPrettyProviderVerdict(msg.Verdict))
+	v, ok := model.providerRows[msg.Provider]
+	if !ok {
+		v = dnsPendingVerdicts()
+	}
+	v.dohVerdict = msg.Verdict
+	model.providerRows[msg.Provider] = v
+	return dnsUpdateProviderTable(model)
+}
+
+func dnsProcessDotProvider(msg dnsProviderDotMsg, model dnsModel) dnsModel {
+	model.out.progress <- fmt.Sprintf("[%s] dot: %s", msg.Provider, dnsPrettyProviderVerdict(msg.Verdict))
+	v, ok := model.providerRows[msg.Provider]
+	if !ok {
+		v = dnsPendingVerdicts()
+	}
+	v.dotVerdict = msg.Verdict
+	model.providerRows[msg.Provider] = v
+	return dnsUpdateProviderTable(model)
+}
+
+func dnsProcessDoqProvider(msg dnsProviderDoqMsg, model dnsModel) dnsModel {
+	model.out.progress <- fmt.Sprintf("[%s] doq: %s", msg.Provider, dnsPrettyProviderVerdict(msg.Verdict))
+	v, ok := model.providerRows[msg.Provider]
+	if !ok {
+		v = dnsPendingVerdicts()
+	}
+	v.doqVerdict = msg.Verdict
+	model.providerRows[msg.Provider] = v
+	return dnsUpdateProviderTable(model)
+}
+
+func dnsProcessDoh3Provider(msg dnsProviderDoh3Msg, model dnsModel) dnsModel {
+	model.out.progress <- fmt.Sprintf("[%s] doh3: %s", msg.Provider, dnsPrettyProviderVerdict(msg.Verdict))
+	v, ok := model.providerRows[msg.Provider]
+	if !ok {
+		v = dnsPendingVerdicts()
+	}
+	v.doh3Verdict = msg.Verdict
+	model.providerRows[msg.Provider] = v
+	return dnsUpdateProviderTable(model)
+}
+
+// New provider row: all verdicts are pending until their messages arrive.
+func dnsPendingVerdicts() dnsVerdictModel {
+	return dnsVerdictModel{
+		udpVerdict:  ErrPending,
+		tcpVerdict:  ErrPending,
+		dohVerdict:  ErrPending,
+		dotVerdict:  ErrPending,
+		doqVerdict:  ErrPending,
+		doh3Verdict: ErrPending,
+	}
+}
+
+func dnsProcessLeak(msg dnsLeakMsg, model dnsModel) dnsModel {
+	if msg.Err != nil {
+		model.out.progress <- "dns leak internal err"
+		return model
+	}
+
+	model.out.progress <- "dns leak received"
+	cfg := config.Get().Checkers.Dns
+
+	newRows := []table.Row{}
+	for _, item := range msg.Items {
+		newRows = append(newRows, table.Row{
+			item.Ip,
+			item.Subnet,
+			item.Asn,
+			item.Org,
+			fmt.Sprintf("%s %s", countryIsoToFlagEmoji(item.Location), item.Location),
+		})
+	}
+	rows := append(model.leakTable.Rows(), newRows...)
+
+	// by ip
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return netip.MustParseAddr(a[0]).Compare(netip.MustParseAddr(b[0]))
+	})
+	rows = slices.CompactFunc(rows, func(a, b table.Row) bool { return a[0] == b[0] })
+
+	columns := []table.Column{
+		{Title: "IP", Width: tableCellMaxLen(rows, 0, 2)},
+		{Title: "Prefix", Width: tableCellMaxLen(rows, 1, 6)},
+		{Title: "AS", Width: tableCellMaxLen(rows, 2, 7)},
+		{Title: "Org", Width: tableCellMaxLen(rows, 3, 3)},
+		{Title: "Location", Width: 8},
+	}
+
+	model.leakTable.SetColumns(columns)
+	model.leakTable.SetRows(rows)
+
+	model.tblHeight = max(model.tblHeight, tableHeight(model.leakTable.Rows(), cfg.TableMaxVisibleRows))
+	model.leakTable.SetHeight(model.tblHeight)
+	model.providerTable.SetHeight(model.tblHeight)
+	model.leakTable.SetWidth(tableWidth(model.leakTable.Columns()))
+
+	return model
+}
+
+func dnsUpdateProviderTable(model dnsModel) dnsModel {
+	cfg := config.Get().Checkers.Dns
+	rows := []table.Row{}
+
+	for id, s := range model.providerRows {
+		udp := dnsPrettyProviderVerdict(s.udpVerdict)
+		tcp := dnsPrettyProviderVerdict(s.tcpVerdict)
+		doh := dnsPrettyProviderVerdict(s.dohVerdict)
+		dot := dnsPrettyProviderVerdict(s.dotVerdict)
+		doq := dnsPrettyProviderVerdict(s.doqVerdict)
+		doh3 := dnsPrettyProviderVerdict(s.doh3Verdict)
+		row := table.Row{id, udp, tcp, doh, dot, doq, doh3}
+		rows = append(rows, row)
+	}
+
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return strings.Compare(a[0], b[0]) // by provider name
+	})
+	columns := []table.Column{
+		{Title: "Provider", Width: tableCellMaxLen(rows, 0, 14)},
+		{Title: "Plain/UDP", Width: tableCellMaxLen(rows, 1, 14)},
+		{Title: "Plain/TCP", Width: tableCellMaxLen(rows, 2, 14)},
+		{Title: "DoH", Width: tableCellMaxLen(rows, 3, 14)},
+		{Title: "DoT", Width: tableCellMaxLen(rows, 4, 14)},
+		{Title: "DoQ", Width: tableCellMaxLen(rows, 5, 14)},
+		{Title: "DoH3", Width: tableCellMaxLen(rows, 6, 14)},
+	}
+
+	model.providerTable.SetColumns(columns)
+	model.providerTable.SetRows(rows)
+
+	model.tblHeight = max(model.tblHeight, tableHeight(model.providerTable.Rows(), cfg.TableMaxVisibleRows))
+	model.providerTable.SetHeight(model.tblHeight)
+	model.leakTable.SetHeight(model.tblHeight)
+	model.providerTable.SetWidth(tableWidth(model.providerTable.Columns()))
+
+	return model
+}
+
+func dnsInitModel() dnsModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	providerTable := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	leakTable := table.New(
+		table.WithFocused(false),
+		table.WithStyles(tableStyle(false)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return dnsModel{
 		inited:        true,
 		ctx:           ctx,
 		cancel:        cancel,
@@ -629,3 +2002,110 @@ func dnsInitModel() dnsModel {
 		leakTable:     leakTable,
 	}
 }
+
+func compressionUpdate(model compressionModel, msg tea.Msg) (compressionModel, tea.Cmd) {
+	if !model.inited {
+		switch msg.(type) {
+		case compressionInitMsg:
+			model = compressionInitModel()
+			return model, tea.Batch(model.spinner.Tick, compressionProducerStartCmd(model.ctx))
+		}
+
+		return model, nil
+	}
+
+	switch msg := msg.(type) {
+	case compressionProducerStartedMsg:
+		model.out = msg.out
+		return model, compressionConsumerCmd(model.out)
+	case compressionItemMsg:
+		return compressionProcessItem(msg, model), tea.Batch(compressionConsumerCmd(model.out), tea.ClearScreen)
+	case compressionProducerDoneMsg:
+		model.fetching = false
+		return model, nil
+	case spinner.TickMsg:
+		if model.fetching {
+			var cmd tea.Cmd
+			model.spinner, cmd = model.spinner.Update(msg)
+			return model, cmd
+		}
+	case returnedToMenuMsg:
+		if model.cancel != nil {
+			model.cancel()
+		}
+		model = compressionModel{}
+		return model, nil
+	}
+
+	var cmd tea.Cmd
+	model.table, cmd = model.table.Update(msg)
+	return model, cmd
+}
+
+func compressionProcessItem(msg compressionItemMsg, model compressionModel) compressionModel {
+	cfg := config.Get().Checkers.Compression
+	model.progress = fmt.Sprintf(`compression checker => "%s" (%s) is ready`, msg.Url, msg.Encoding)
+
+	status, ratio := " — ", " — "
+	if msg.HttpStatus != 0 {
+		status = fmt.Sprintf("%d", msg.HttpStatus)
+	}
+	if msg.Compressed > 0 {
+		ratio = fmt.Sprintf("x%.2f", float64(msg.Decompressed)/float64(msg.Compressed))
+	}
+
+	row := table.Row{
+		msg.Url,
+		msg.Encoding,
+		status,
+		fmt.Sprintf("%d", msg.Compressed),
+		fmt.Sprintf("%d", msg.Decompressed),
+		ratio,
+		compressionPrettyVerdict(msg.Verdict),
+	}
+
+	rows := append(model.table.Rows(), row)
+	slices.SortFunc(rows, func(a, b table.Row) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1])) // by url, then by encoding
+	})
+
+	columns := []table.Column{
+		{Title: "URL", Width: tableCellMaxLen(rows, 0, 3)},
+		{Title: "Encoding", Width: tableCellMaxLen(rows, 1, 8)},
+		{Title: "HTTP", Width: tableCellMaxLen(rows, 2, 4)},
+		{Title: "Compr", Width: tableCellMaxLen(rows, 3, 5)},
+		{Title: "Decompr", Width: tableCellMaxLen(rows, 4, 7)},
+		{Title: "Ratio", Width: tableCellMaxLen(rows, 5, 5)},
+		{Title: "Verdict", Width: tableCellMaxLen(rows, 6, 7)},
+	}
+
+	model.table.SetColumns(columns)
+	model.table.SetRows(rows)
+	model.table.SetHeight(tableHeight(model.table.Rows(), cfg.TableMaxVisibleRows))
+	model.table.SetWidth(tableWidth(model.table.Columns()))
+
+	return model
+}
+
+func compressionInitModel() compressionModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	spin := spinner.New()
+	spin.Spinner = spinnerType
+	spin.Style = spinnerStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithStyles(tableStyle(true)),
+		table.WithKeyMap(tableKeyMap()),
+	)
+
+	return compressionModel{
+		inited:   true,
+		ctx:      ctx,
+		cancel:   cancel,
+		fetching: true,
+		table:    t,
+		spinner:  spin,
+	}
+}
@@ -51,6 +51,8 @@ func (rm rootModel) render() string {
 	switch rm.router.Tab {
 	case allTab:
 		s += allView(rm.allModel)
+	case diagnoseTab:
+		s += diagnoseView(rm.diagnoseModel)
 	case menuTab:
 		s += rm.router.Menu.View()
 	case whoamiTab:
@@ -61,6 +63,30 @@ func (rm rootModel) render() string {
 		s += webhostView(rm.webhostModel)
 	case dnsTab:
 		s += dnsView(rm.dnsModel)
+	case compressionTab:
+		s += compressionView(rm.compressionModel)
+	case quicTab:
+		s += quicView(rm.quicModel)
+	case httphostTab:
+		s += httphostView(rm.httphostModel)
+	case fingerprintTab:
+		s += fingerprintView(rm.fingerprintModel)
+	case bypassTab:
+		s += bypassView(rm.bypassModel)
+	case echTab:
+		s += echView(rm.echModel)
+	case tunnelTab:
+		s += tunnelView(rm.tunnelModel)
+	case udpvpnTab:
+		s += udpvpnView(rm.udpvpnModel)
+	case stunTab:
+		s += stunView(rm.stunModel)
+	case idleTab:
+		s += idleView(rm.idleModel)
+	case portscanTab:
+		s += portscanView(rm.portscanModel)
+	case dnsinjectTab:
+		s += dnsinjectView(rm.dnsinjectModel)
 	case updaterTab:
 		s += updaterView(rm.updaterModel)
 	}
@@ -114,7 +140,7 @@ func whoamiView(model whoamiModel) string {
 
 	r := model.result
 	emj := countryIsoToFlagEmoji(r.Location)
-	return fmt.Sprintf("IP: %s\nSubnet: %s\nOrg: %s (%s)\nLocation: %s %s\nTTLB: %d ms", r.Ip, r.Subnet, r.Org, r.Asn, emj, r.Location, r.Ttlb.Milliseconds())
+	return fmt.Sprintf("IP: %s\nSubnet: %s\nOrg: %s (%s)\nLocation: %s %s\nTTLB: %d ms\nNAT (udp): %s", r.Ip, r.Subnet, r.Org, r.Asn, emj, r.Location, r.Ttlb.Milliseconds(), whoamiPrettyNat(r))
 }
 
 func allView(model allModel) string {
@@ -131,6 +157,26 @@ func allView(model allModel) string {
 	return out
 }
 
+func diagnoseView(model diagnoseModel) string {
+	r := fmt.Sprintf("Host: %s\n\n", model.host)
+	for _, x := range model.steps {
+		r += fmt.Sprintf("%s %-10s %s\n", diagnosePrettyMark(x.Err), x.Layer, subtleStyle.Render(diagnosePrettyErr(x.Err)))
+		for _, e := range x.Evidence {
+			r += subtleStyle.Render("   └ "+e) + "\n"
+		}
+	}
+
+	if model.fetching {
+		return r + fmt.Sprintf("\n%s diagnosing...", model.spinner.View())
+	}
+
+	r += "\n"
+	if cause := checkers.DiagnoseRootCause(model.steps); cause != nil {
+		return r + dangerStyle.Render(fmt.Sprintf("Root cause (%s layer): %s", cause.Layer, cause.Err))
+	}
+	return r + okStyle.Render("No interference is found ;)")
+}
+
 func cidrwhitelistView(model cidrwhitelistModel) string {
 	if model.fetching {
 		return fmt.Sprintf("%s fetching...", model.spinner.View())
@@ -165,9 +211,12 @@ func webhostView(model webhostModel) string {
 
 		inner := model.table.View() +
 			"\n " + model.table.HelpView() +
-			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s; enter: throughput curve", cursor, total, over))
 
 		r += tableOuterBorderStyle(true).Render(inner) + "\n\n"
+		if model.detail {
+			r += webhostSeriesView(model) + "\n\n"
+		}
 	}
 	if model.fetching {
 		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
@@ -179,6 +228,333 @@ func webhostView(model webhostModel) string {
 	return r
 }
 
+// Detail view: the throughput curve of the host under the cursor.
+func webhostSeriesView(model webhostModel) string {
+	const width = 100
+	row := model.table.SelectedRow()
+	if row == nil {
+		return ""
+	}
+
+	title := fmt.Sprintf("📈 %s / %s: ", row[0], row[4])
+	series := model.series[webhostRowKey(row)]
+	if series == nil {
+		return title + subtleStyle.Render("no throughput series (enable throughput-series in config)")
+	}
+	if len(series.Samples) == 0 {
+		return title + webhostPrettySeries(series)
+	}
+
+	rx := series.Samples[len(series.Samples)-1]
+	inner := sparkline(series.Samples, width) + "\n" +
+		subtleStyle.Render(fmt.Sprintf("rx per %s: %d steps, %.1f KB total", series.Step, len(series.Samples), float64(rx)/1024))
+	return title + webhostPrettySeries(series) + "\n" + tableOuterBorderStyle(false).Render(inner)
+}
+
+func quicView(model quicModel) string {
+	var r string
+	cfg := config.Get().Checkers.Quic
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	if model.farmTimeout {
+		r += fmt.Sprintf("⏰ farming timeout exceeded (%s)\n", config.Get().Checkers.Webhost.FarmTimeout.String())
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func fingerprintView(model fingerprintModel) string {
+	var r string
+	cfg := config.Get().Checkers.Fingerprint
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("cell: http/1.1 alpn / original alpn; 🟢 pass, 🐢 throttled, 🔴 dropped, ⛔ host unavailable, ⚠️ skipped") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	if model.farmTimeout {
+		r += fmt.Sprintf("⏰ farming timeout exceeded (%s)\n", config.Get().Checkers.Webhost.FarmTimeout.String())
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func bypassView(model bypassModel) string {
+	var r string
+	cfg := config.Get().Checkers.Bypass
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("strategies are evaluated only for blocked hosts (i.e. if the baseline handshake fails)") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	if model.farmTimeout {
+		r += fmt.Sprintf("⏰ farming timeout exceeded (%s)\n", config.Get().Checkers.Webhost.FarmTimeout.String())
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func echView(model echModel) string {
+	var r string
+	cfg := config.Get().Checkers.Ech
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	if model.farmTimeout {
+		r += fmt.Sprintf("⏰ farming timeout exceeded (%s)\n", config.Get().Checkers.Webhost.FarmTimeout.String())
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func idleView(model idleModel) string {
+	var r string
+	cfg := config.Get().Checkers.Idle
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("cell: the longest idle interval that survived; 🟢 all, 🟡 some, 🔴 none (with the reason of the first kill)") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	if model.farmTimeout {
+		r += fmt.Sprintf("⏰ farming timeout exceeded (%s)\n", config.Get().Checkers.Webhost.FarmTimeout.String())
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func portscanView(model portscanModel) string {
+	var r string
+	cfg := config.Get().Checkers.Portscan
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("cell: 🟢 open, 🟡 refused (the port is closed, but reachable), 🔴 filtered or reset; baseline is the port the host is farmed by") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	if model.farmTimeout {
+		r += fmt.Sprintf("⏰ farming timeout exceeded (%s)\n", config.Get().Checkers.Webhost.FarmTimeout.String())
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func dnsinjectView(model dnsinjectModel) string {
+	var r string
+	cfg := config.Get().Checkers.Dnsinject
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("row: sensitive or control domain queried on the host; any answer from a silent ip is injected") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	if model.farmTimeout {
+		r += fmt.Sprintf("⏰ farming timeout exceeded (%s)\n", config.Get().Checkers.Webhost.FarmTimeout.String())
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func httphostView(model httphostModel) string {
+	var r string
+	cfg := config.Get().Checkers.HttpHost
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func tunnelView(model tunnelModel) string {
+	var r string
+	cfg := config.Get().Checkers.Tunnel
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("🟢 answered or same as control; 🔴 reacted unlike control (i.e. blocked); ⚠️ endpoint unavailable or skipped") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func udpvpnView(model udpvpnModel) string {
+	var r string
+	cfg := config.Get().Checkers.Udpvpn
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("🟢 replied or icmp; 🔴 silence unlike control (i.e. dropped); ⚪️ silence like control (inconclusive); ⚠️ endpoint unavailable or skipped") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
+func stunView(model stunModel) string {
+	var r string
+	cfg := config.Get().Checkers.Stun
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n"
+		r += subtleStyle.Render("cell: rtt (avg) / loss; many servers don't support tcp, so only udp matters for the verdict") + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
 func dnsView(model dnsModel) string {
 	var r string
 	providerTotal := len(model.providerTable.Rows())
@@ -231,6 +607,31 @@ func dnsTableHelpView() string {
 	return subtleStyle.Render("↑/↓ up/down; ←/→ left/right table")
 }
 
+func compressionView(model compressionModel) string {
+	var r string
+	cfg := config.Get().Checkers.Compression
+	total := len(model.table.Rows())
+
+	if total > 0 {
+		cursor := model.table.Cursor() + 1
+		over := ""
+		if total > cfg.TableMaxVisibleRows {
+			over = " 👀"
+		}
+
+		inner := model.table.View() +
+			"\n " + model.table.HelpView() +
+			subtleStyle.Render(fmt.Sprintf("; cursor: %d/%d%s", cursor, total, over))
+
+		r += tableOuterBorderStyle(true).Render(inner) + "\n\n"
+	}
+	if model.fetching {
+		r += fmt.Sprintf("%s %s\n", model.spinner.View(), model.progress)
+	}
+	r += fmt.Sprintf("count: %d pcs.", total)
+	return r
+}
+
 func updaterView(model updaterModel) string {
 	if model.err != nil {
 		return fmt.Sprintf("⚠️ error: %v", model.err)